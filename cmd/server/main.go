@@ -12,6 +12,7 @@ import (
 	"kerkerker-douban-service/internal/handler"
 	"kerkerker-douban-service/internal/middleware"
 	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/scheduler"
 	"kerkerker-douban-service/internal/service"
 	"kerkerker-douban-service/pkg/httpclient"
 
@@ -52,29 +53,98 @@ func main() {
 	metrics.RecordServerStart(context.Background())
 	log.Info().Msg("📊 Metrics enabled")
 
+	// Initialize Douban-to-TMDB mapping store
+	mappings, err := repository.NewMappingStore(cfg.RedisURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize TMDB mapping store")
+	}
+	defer mappings.Close()
+
 	// Initialize HTTP client with proxy support
-	httpClient := httpclient.NewClient(cfg.DoubanProxies)
+	httpOpts := httpclient.DefaultOptions()
+	httpOpts.Timeout = cfg.DoubanHTTPTimeout
+	httpOpts.MaxBodyBytes = cfg.DoubanMaxBodyBytes
+	httpOpts.MaxIdleConns = cfg.DoubanMaxIdleConns
+	httpOpts.MaxIdleConnsPerHost = cfg.DoubanMaxIdleConnsPerHost
+	httpOpts.IdleConnTimeout = cfg.DoubanIdleConnTimeout
+	httpOpts.TLSHandshakeTimeout = cfg.DoubanTLSHandshakeTimeout
+	httpOpts.ResponseHeaderTimeout = cfg.DoubanResponseHeaderTimeout
+	httpOpts.DisableHTTP2 = cfg.DoubanDisableHTTP2
+	httpClient := httpclient.NewClient(cfg.DoubanProxies, httpOpts)
 	if httpClient.HasProxy() {
 		log.Info().Int("count", httpClient.ProxyCount()).Msg("🔀 Proxy enabled")
 	}
+	if cfg.DoubanProfilesFile != "" {
+		count, err := httpClient.LoadProfiles(cfg.DoubanProfilesFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load User-Agent profiles")
+		}
+		log.Info().Int("count", count).Msg("🧬 User-Agent profiles loaded")
+	}
+	if cfg.DoubanSessionFile != "" {
+		count, err := httpClient.LoadSessions(cfg.DoubanSessionFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load Douban sessions")
+		}
+		log.Info().Int("count", count).Msg("🍪 Douban sessions loaded")
+	}
 
 	// Initialize services
-	doubanService := service.NewDoubanService(httpClient)
-	tmdbService := service.NewTMDBService(cfg.TMDBAPIKeys, cfg.TMDBBaseURL, cfg.TMDBImageBase)
+	doubanService := service.NewDoubanService(httpClient, metrics)
+	tmdbService := service.NewTMDBService(cfg.TMDBAPIKeys, cfg.TMDBBaseURL, cfg.TMDBImageBase, cache, cfg.CacheTTLTMDB, cfg.CacheTTLTMDBMiss)
 	if tmdbService.IsConfigured() {
 		log.Info().Int("keys", tmdbService.KeyCount()).Msg("🎬 TMDB service enabled (轮询模式)")
 	}
 
+	// 外部元数据提供方：手动覆盖优先，其次 TMDB
+	staticProvider := service.NewStaticProvider()
+	if cfg.MetadataOverridesFile != "" {
+		staticProvider, err = service.LoadStaticProvider(cfg.MetadataOverridesFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load metadata overrides")
+		}
+		log.Info().Int("count", staticProvider.Count()).Msg("📝 Metadata overrides loaded")
+	}
+	tmdbMatcher := service.NewTMDBMatcher(tmdbService, mappings, staticProvider)
+	metadata, err := service.NewMetadataChain([]service.MetadataProvider{
+		staticProvider,
+		service.NewTMDBProvider(tmdbService, tmdbMatcher),
+	}, cfg.MetadataProviders)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid METADATA_PROVIDERS")
+	}
+
 	// Initialize handlers with configured cache TTL
-	heroHandler := handler.NewHeroHandler(doubanService, tmdbService, cache, cfg.CacheTTLHero)
-	categoryHandler := handler.NewCategoryHandler(doubanService, cache)
-	detailHandler := handler.NewDetailHandler(doubanService, cache)
+	heroHandler := handler.NewHeroHandler(doubanService, metadata, cache, cfg.CacheTTLHero, cfg.HeroTrailers)
+	categoryHandler := handler.NewCategoryHandler(doubanService, cache, cfg.CacheTTLDoulist)
+	detailHandler := handler.NewDetailHandler(doubanService, metadata, cache, cfg.TMDBRegion)
 	latestHandler := handler.NewLatestHandler(doubanService, cache)
 	moviesHandler := handler.NewMoviesHandler(doubanService, cache)
 	tvHandler := handler.NewTVHandler(doubanService, cache)
 	newHandler := handler.NewNewHandler(doubanService, cache)
 	searchHandler := handler.NewSearchHandler(doubanService, cache)
+	episodesHandler := handler.NewEpisodesHandler(doubanService, tmdbService, tmdbMatcher, cache)
+	videosHandler := handler.NewVideosHandler(doubanService, tmdbService, tmdbMatcher, cache)
+	chartsHandler := handler.NewChartsHandler(doubanService, cache)
+	cinemaHandler := handler.NewCinemaHandler(doubanService, cache, cfg.CinemaDefaultCity)
+	celebrityHandler := handler.NewCelebrityHandler(doubanService, cache)
+	top250Handler := handler.NewTop250Handler(doubanService, cache, cfg.CacheTTLTop250)
+
+	// 注册豆列分类，并由定时任务保持缓存
+	doulistIDs := handler.RegisterDoulistCategories(cfg.DoulistCategories)
+	doulistHandler := handler.NewDoulistHandler(doubanService, cache, cfg.CacheTTLDoulist, doulistIDs)
 	adminHandler := handler.NewAdminHandler(doubanService, tmdbService, metrics)
+	mappingHandler := handler.NewMappingHandler(tmdbService, mappings, cache)
+	tmdbKeyHandler := handler.NewTMDBKeyHandler(tmdbService)
+	artworkHandler := handler.NewArtworkHandler(doubanService, tmdbService, tmdbMatcher, cache)
+
+	// Background jobs, stopped on shutdown
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go scheduler.Every(jobCtx, "top250", 24*time.Hour, top250Handler.Refresh)
+	if len(doulistIDs) > 0 {
+		go scheduler.Every(jobCtx, "doulist", cfg.DoulistRefreshInterval, doulistHandler.Refresh)
+	}
 
 	// Setup router
 	r := gin.New()
@@ -104,11 +174,27 @@ func main() {
 		api.GET("/hero", heroHandler.GetHero)
 		api.GET("/category", categoryHandler.GetCategory)
 		api.GET("/detail/:id", detailHandler.GetDetail)
+		api.GET("/detail/:id/comments", detailHandler.GetComments)
+		api.GET("/detail/:id/reviews", detailHandler.GetReviews)
+		api.GET("/detail/:id/reviews/:reviewId", detailHandler.GetReviewContent)
+		api.GET("/detail/:id/photos", detailHandler.GetPhotos)
+		api.GET("/detail/:id/episodes", episodesHandler.GetEpisodes)
+		api.GET("/detail/:id/videos", videosHandler.GetVideos)
+		api.GET("/celebrity/:id", celebrityHandler.GetCelebrity)
+		api.GET("/celebrity/:id/works", celebrityHandler.GetCelebrityWorks)
 		api.GET("/latest", latestHandler.GetLatest)
 		api.GET("/movies", moviesHandler.GetMovies)
 		api.GET("/tv", tvHandler.GetTV)
 		api.GET("/new", newHandler.GetNew)
 		api.GET("/search", searchHandler.Search)
+		api.GET("/top250", top250Handler.GetTop250)
+		api.GET("/charts", chartsHandler.ListGenres)
+		api.GET("/charts/:genre", chartsHandler.GetChart)
+		api.GET("/cinema/nowplaying", cinemaHandler.GetNowPlaying)
+		api.GET("/cinema/coming", cinemaHandler.GetComingSoon)
+		api.GET("/cinema/coming.ics", cinemaHandler.GetComingSoonCalendar)
+		api.GET("/doulist/:id", doulistHandler.GetDoulist)
+		api.GET("/artwork/:doubanId", artworkHandler.GetArtwork)
 		api.POST("/search", searchHandler.GetSearchTags)
 	}
 
@@ -119,18 +205,39 @@ func main() {
 		// Analytics（查询也需要认证保护）
 		admin.GET("/analytics", adminHandler.GetAnalytics)
 		admin.GET("/analytics/endpoint", adminHandler.GetEndpointStats)
+		admin.GET("/analytics/drift", adminHandler.GetSchemaDrift)
+		admin.GET("/sessions", adminHandler.GetSessions)
 		admin.DELETE("/analytics", adminHandler.ResetAnalytics)
 
+		// TMDB 映射
+		admin.GET("/tmdb/mappings", mappingHandler.ListMappings)
+		admin.PUT("/tmdb/mappings/:id", mappingHandler.SetMapping)
+		admin.DELETE("/tmdb/mappings/:id", mappingHandler.DeleteMapping)
+
+		// TMDB API Key 管理
+		admin.GET("/tmdb/keys", tmdbKeyHandler.ListKeys)
+		admin.POST("/tmdb/keys", tmdbKeyHandler.AddKey)
+		admin.DELETE("/tmdb/keys/:id", tmdbKeyHandler.RemoveKey)
+		admin.POST("/tmdb/keys/:id/test", tmdbKeyHandler.TestKey)
+		admin.DELETE("/tmdb", adminHandler.DeleteTMDBCache)
+
 		// 缓存管理
 		admin.DELETE("/hero", heroHandler.DeleteHeroCache)
 		admin.DELETE("/category", categoryHandler.DeleteCategoryCache)
 		admin.DELETE("/detail/:id", detailHandler.DeleteDetailCache)
 		admin.DELETE("/detail", detailHandler.DeleteAllDetailCache)
+		admin.DELETE("/photos", detailHandler.DeletePhotosCache)
+		admin.DELETE("/celebrity", celebrityHandler.DeleteCelebrityCache)
 		admin.DELETE("/latest", latestHandler.DeleteLatestCache)
 		admin.DELETE("/movies", moviesHandler.DeleteMoviesCache)
 		admin.DELETE("/tv", tvHandler.DeleteTVCache)
 		admin.DELETE("/new", newHandler.DeleteNewCache)
 		admin.DELETE("/search", searchHandler.DeleteSearchCache)
+		admin.DELETE("/top250", top250Handler.DeleteTop250Cache)
+		admin.DELETE("/charts", chartsHandler.DeleteChartsCache)
+		admin.DELETE("/cinema", cinemaHandler.DeleteCinemaCache)
+		admin.DELETE("/doulist", doulistHandler.DeleteDoulistCache)
+		admin.DELETE("/artwork", artworkHandler.DeleteArtworkCache)
 	}
 
 	// 日志输出认证状态
@@ -163,6 +270,7 @@ func main() {
 	<-quit
 
 	log.Info().Msg("🛑 Shutting down server...")
+	stopJobs()
 
 	// Give outstanding requests a deadline for completion
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
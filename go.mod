@@ -3,6 +3,7 @@ module kerkerker-douban-service
 go 1.23
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/gin-contrib/cors v1.7.2
 	github.com/gin-gonic/gin v1.10.0
 	github.com/redis/go-redis/v9 v9.7.0
@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 // Config holds all configuration for the service
@@ -16,6 +18,21 @@ type Config struct {
 	TMDBAPIKeys   []string // 支持多个 API Key 轮询
 	TMDBBaseURL   string
 	TMDBImageBase string
+	TMDBRegion    string // 观看渠道默认地区（ISO 3166-1）
+
+	// 豆瓣请求身份
+	DoubanSessionFile  string // 会话 cookie 文件（敏感信息，不要提交到仓库）
+	DoubanProfilesFile string // User-Agent 指纹配置文件，为空使用内置配置
+
+	// 豆瓣 HTTP 客户端调优
+	DoubanHTTPTimeout           time.Duration // 单次请求总超时
+	DoubanMaxBodyBytes          int64         // 响应体（解压后）最大字节数
+	DoubanMaxIdleConns          int           // 连接池最大空闲连接数
+	DoubanMaxIdleConnsPerHost   int           // 每个主机最大空闲连接数
+	DoubanIdleConnTimeout       time.Duration // 空闲连接保持时间
+	DoubanTLSHandshakeTimeout   time.Duration // TLS 握手超时
+	DoubanResponseHeaderTimeout time.Duration // 等待响应头超时
+	DoubanDisableHTTP2          bool          // 禁用 HTTP/2
 
 	// 缓存 TTL 配置（差异化）
 	CacheTTLHero     time.Duration // Hero Banner 缓存时间
@@ -23,7 +40,24 @@ type Config struct {
 	CacheTTLCategory time.Duration // 分类缓存时间
 	CacheTTLSearch   time.Duration // 搜索缓存时间
 	CacheTTLDefault  time.Duration // 默认缓存时间
+	CacheTTLTop250   time.Duration // Top 250 缓存时间（每日定时刷新）
+	CacheTTLDoulist  time.Duration // 豆列缓存时间（定时刷新）
+	CacheTTLTMDB     time.Duration // TMDB 搜索/匹配/图片结果缓存时间
+	CacheTTLTMDBMiss time.Duration // TMDB 未匹配结果缓存时间
+
+	// Hero Banner
+	HeroTrailers bool // Hero 数据中附带可播放的预告片
+
+	// 院线
+	CinemaDefaultCity string // 未指定城市时使用的豆瓣城市（拼音，如 beijing）
 
+	// 豆列
+	DoulistCategories      map[string]string // 注册为分类的豆列，分类名 -> 豆列 ID
+	DoulistRefreshInterval time.Duration     // 已注册豆列的刷新间隔
+
+	// 外部元数据
+	MetadataOverridesFile string              // 手动覆盖的元数据文件（JSON，按豆瓣 ID）
+	MetadataProviders     map[string][]string // 字段 -> 按顺序查询的提供方
 	// Admin API 认证
 	AdminAPIKey string // 为空则不启用认证
 }
@@ -49,6 +83,31 @@ func Load() *Config {
 		}
 	}
 
+	// 豆列分类，格式 name:id，多个用逗号分隔
+	doulistCategories := map[string]string{}
+	for _, entry := range strings.Split(os.Getenv("DOULIST_CATEGORIES"), ",") {
+		name, id, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if ok && strings.TrimSpace(name) != "" && strings.TrimSpace(id) != "" {
+			doulistCategories[strings.TrimSpace(name)] = strings.TrimSpace(id)
+		}
+	}
+
+	// 元数据提供方顺序，格式 field:p1,p2，多个字段用分号分隔
+	metadataProviders := map[string][]string{}
+	for _, entry := range strings.Split(os.Getenv("METADATA_PROVIDERS"), ";") {
+		field, names, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || strings.TrimSpace(field) == "" {
+			continue
+		}
+		providers := []string{}
+		for _, name := range strings.Split(names, ",") {
+			if trimmed := strings.TrimSpace(name); trimmed != "" {
+				providers = append(providers, trimmed)
+			}
+		}
+		metadataProviders[strings.TrimSpace(field)] = providers
+	}
+
 	return &Config{
 		Port:          getEnv("PORT", "8080"),
 		GinMode:       getEnv("GIN_MODE", "debug"),
@@ -57,13 +116,42 @@ func Load() *Config {
 		TMDBAPIKeys:   tmdbKeys,
 		TMDBBaseURL:   getEnv("TMDB_BASE_URL", "https://api.themoviedb.org/3"),
 		TMDBImageBase: getEnv("TMDB_IMAGE_BASE", "https://image.tmdb.org/t/p/original"),
+		TMDBRegion:    strings.ToUpper(getEnv("TMDB_WATCH_REGION", "CN")),
+
+		// 豆瓣会话与 UA 指纹
+		DoubanSessionFile:  getEnv("DOUBAN_SESSION_FILE", ""),
+		DoubanProfilesFile: getEnv("DOUBAN_UA_PROFILES_FILE", ""),
+
+		// 豆瓣 HTTP 客户端（单位：秒 / KB）
+		DoubanHTTPTimeout:           getDurationSeconds("DOUBAN_HTTP_TIMEOUT", 10),
+		DoubanMaxBodyBytes:          int64(getNonNegativeInt("DOUBAN_HTTP_MAX_BODY_KB", 5120)) * 1024, // 0 表示不限制
+		DoubanMaxIdleConns:          getInt("DOUBAN_HTTP_MAX_IDLE_CONNS", 100),
+		DoubanMaxIdleConnsPerHost:   getInt("DOUBAN_HTTP_MAX_IDLE_CONNS_PER_HOST", 10),
+		DoubanIdleConnTimeout:       getDurationSeconds("DOUBAN_HTTP_IDLE_CONN_TIMEOUT", 90),
+		DoubanTLSHandshakeTimeout:   getDurationSeconds("DOUBAN_HTTP_TLS_TIMEOUT", 5),
+		DoubanResponseHeaderTimeout: getDurationSeconds("DOUBAN_HTTP_RESPONSE_HEADER_TIMEOUT", 8),
+		DoubanDisableHTTP2:          getBool("DOUBAN_HTTP_DISABLE_HTTP2", false),
 
 		// 缓存 TTL（可通过环境变量覆盖，单位：分钟）
-		CacheTTLHero:     getDurationMinutes("CACHE_TTL_HERO", 360),    // 6 小时
-		CacheTTLDetail:   getDurationMinutes("CACHE_TTL_DETAIL", 1440), // 24 小时
-		CacheTTLCategory: getDurationMinutes("CACHE_TTL_CATEGORY", 60), // 1 小时
-		CacheTTLSearch:   getDurationMinutes("CACHE_TTL_SEARCH", 30),   // 30 分钟
-		CacheTTLDefault:  getDurationMinutes("CACHE_TTL_DEFAULT", 60),  // 1 小时
+		CacheTTLHero:     getDurationMinutes("CACHE_TTL_HERO", 360),       // 6 小时
+		CacheTTLDetail:   getDurationMinutes("CACHE_TTL_DETAIL", 1440),    // 24 小时
+		CacheTTLCategory: getDurationMinutes("CACHE_TTL_CATEGORY", 60),    // 1 小时
+		CacheTTLSearch:   getDurationMinutes("CACHE_TTL_SEARCH", 30),      // 30 分钟
+		CacheTTLDefault:  getDurationMinutes("CACHE_TTL_DEFAULT", 60),     // 1 小时
+		CacheTTLTop250:   getDurationMinutes("CACHE_TTL_TOP250", 2880),    // 48 小时
+		CacheTTLDoulist:  getDurationMinutes("CACHE_TTL_DOULIST", 1440),   // 24 小时
+		CacheTTLTMDB:     getDurationMinutes("CACHE_TTL_TMDB", 10080),     // 7 天
+		CacheTTLTMDBMiss: getDurationMinutes("CACHE_TTL_TMDB_MISS", 1440), // 24 小时
+
+		HeroTrailers: getBool("HERO_TRAILERS", false),
+
+		CinemaDefaultCity: getEnv("CINEMA_DEFAULT_CITY", "beijing"),
+
+		DoulistCategories:      doulistCategories,
+		DoulistRefreshInterval: getDurationMinutes("DOULIST_REFRESH_INTERVAL", 360), // 6 小时
+
+		MetadataOverridesFile: getEnv("METADATA_OVERRIDES_FILE", ""),
+		MetadataProviders:     metadataProviders,
 
 		// Admin API 密钥
 		AdminAPIKey: getEnv("ADMIN_API_KEY", ""),
@@ -77,6 +165,43 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getNonNegativeInt is like getInt but accepts 0, e.g. to disable a limit.
+// Invalid values fall back to the default with a warning.
+func getNonNegativeInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Warn().Str("key", key).Str("value", value).Int("default", defaultValue).Msg("⚠️ 无效的配置值，使用默认值")
+		return defaultValue
+	}
+	return n
+}
+
+func getBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getDurationSeconds(key string, defaultSeconds int) time.Duration {
+	return time.Duration(getInt(key, defaultSeconds)) * time.Second
+}
+
 func getDurationMinutes(key string, defaultMinutes int) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if minutes, err := strconv.Atoi(value); err == nil && minutes > 0 {
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"kerkerker-douban-service/internal/repository"
@@ -33,6 +34,7 @@ func (h *AdminHandler) GetStatus(c *gin.Context) {
 		"status":        "ok",
 		"proxy_enabled": h.doubanService.HasProxy(),
 		"proxy_count":   h.doubanService.ProxyCount(),
+		"session_count": h.doubanService.ActiveSessionCount(),
 		"tmdb_enabled":  h.tmdbService.IsConfigured(),
 	})
 }
@@ -86,6 +88,35 @@ func (h *AdminHandler) GetEndpointStats(c *gin.Context) {
 	})
 }
 
+// GetSessions returns the health of the Douban cookie sessions
+// GET /api/v1/sessions
+func (h *AdminHandler) GetSessions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": h.doubanService.SessionStatus(),
+	})
+}
+
+// GetSchemaDrift returns upstream schema drift counters and payload samples
+// GET /api/v1/analytics/drift
+func (h *AdminHandler) GetSchemaDrift(c *gin.Context) {
+	ctx := context.Background()
+
+	stats, err := h.metrics.GetSchemaDriftStats(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":  500,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": stats,
+	})
+}
+
 // ResetAnalytics resets all analytics data
 // DELETE /api/v1/analytics
 func (h *AdminHandler) ResetAnalytics(c *gin.Context) {
@@ -104,3 +135,23 @@ func (h *AdminHandler) ResetAnalytics(c *gin.Context) {
 		"message": "所有统计数据已重置",
 	})
 }
+
+// DeleteTMDBCache clears the cached TMDB search, find and image results
+// DELETE /api/v1/tmdb
+func (h *AdminHandler) DeleteTMDBCache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.tmdbService.ClearCache(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":  500,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": fmt.Sprintf("TMDB 缓存已清除 (%d 条)", deleted),
+	})
+}
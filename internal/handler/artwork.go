@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const artworkCacheKeyPrefix = "tmdb:artwork:"
+
+// ArtworkHandler handles TMDB artwork API requests
+type ArtworkHandler struct {
+	doubanService *service.DoubanService
+	tmdbService   *service.TMDBService
+	matcher       *service.TMDBMatcher
+	cache         *repository.Cache
+}
+
+// NewArtworkHandler creates a new ArtworkHandler
+func NewArtworkHandler(douban *service.DoubanService, tmdb *service.TMDBService, matcher *service.TMDBMatcher, cache *repository.Cache) *ArtworkHandler {
+	return &ArtworkHandler{
+		doubanService: douban,
+		tmdbService:   tmdb,
+		matcher:       matcher,
+		cache:         cache,
+	}
+}
+
+// GetArtwork returns the TMDB backdrops, posters and logos of a Douban subject
+// GET /api/v1/artwork/:doubanId
+func (h *ArtworkHandler) GetArtwork(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("doubanId")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣ID",
+		})
+		return
+	}
+	if !h.tmdbService.IsConfigured() {
+		c.JSON(http.StatusServiceUnavailable, model.APIResponse{
+			Code:  503,
+			Error: "未配置 TMDB API Key",
+		})
+		return
+	}
+
+	cacheKey := artworkCacheKeyPrefix + id
+
+	// Check cache
+	var cachedData model.Artwork
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   cachedData,
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Msg("🖼️ 获取 TMDB 图片")
+
+	mapping := h.match(ctx, id)
+	if mapping == nil {
+		c.JSON(http.StatusNotFound, model.APIResponse{
+			Code:  404,
+			Error: "未匹配到 TMDB 条目",
+		})
+		return
+	}
+
+	artwork, err := h.tmdbService.GetImages(service.TMDBMatch{ID: mapping.TMDBID, MediaType: mapping.MediaType})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+	artwork.DoubanID = id
+
+	h.cache.Set(ctx, cacheKey, artwork)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   artwork,
+		Source: "fresh",
+	})
+}
+
+// DeleteArtworkCache clears artwork cache
+// DELETE /api/v1/artwork
+func (h *ArtworkHandler) DeleteArtworkCache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.cache.DeletePattern(ctx, artworkCacheKeyPrefix+"*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: fmt.Sprintf("图片缓存已清除 (%d 条)", deleted),
+	})
+}
+
+// match resolves the subject through its stored mapping or IMDb ID, and
+// falls back to a title search with the subject abstract
+func (h *ArtworkHandler) match(ctx context.Context, id string) *model.TMDBMapping {
+	query := service.MatchQuery{
+		DoubanID: id,
+		IMDbID: func() string {
+			page, err := cachedSubjectPage(ctx, h.cache, h.doubanService, id)
+			if err != nil {
+				return ""
+			}
+			return page.IMDbID
+		},
+	}
+
+	if mapping, err := h.matcher.Match(ctx, query); err == nil && mapping != nil {
+		return mapping
+	}
+
+	abstract, err := h.doubanService.GetSubjectAbstract(id)
+	if err != nil || abstract.Subject == nil {
+		return nil
+	}
+	query.Title = abstract.Subject.Title
+	query.Year = abstract.Subject.ReleaseYear
+	query.IsTV = service.IsTVAbstract(abstract.Subject)
+
+	mapping, err := h.matcher.Match(ctx, query)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("TMDB match failed")
+	}
+	return mapping
+}
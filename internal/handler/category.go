@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"kerkerker-douban-service/internal/model"
 	"kerkerker-douban-service/internal/repository"
@@ -14,34 +15,85 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// Category tag mapping
-var categoryTagMap = map[string]struct {
-	Tag  string
-	Type string
-}{
-	"in_theaters": {Tag: "热门", Type: ""},
-	"hot_movies":  {Tag: "热门", Type: "movie"},
-	"hot_tv":      {Tag: "热门", Type: "tv"},
-	"us_tv":       {Tag: "美剧", Type: "tv"},
-	"jp_tv":       {Tag: "日剧", Type: "tv"},
-	"kr_tv":       {Tag: "韩剧", Type: "tv"},
-	"anime":       {Tag: "日本动画", Type: "tv"},
-	"documentary": {Tag: "纪录片", Type: "tv"},
-	"variety":     {Tag: "综艺", Type: "tv"},
-	"chinese_tv":  {Tag: "国产剧", Type: "tv"},
+// Category kinds
+const (
+	categoryKindTag     = "tag"     // 按标签搜索（search_subjects）
+	categoryKindChart   = "chart"   // 按类型排行榜（chart top_list）
+	categoryKindDoulist = "doulist" // 豆列，见 RegisterDoulistCategories
+)
+
+// categoryConfig describes where a category's subjects come from
+type categoryConfig struct {
+	Kind     string
+	Tag      string // tag: 标签
+	Type     string // tag: movie / tv，空为全部
+	Genre    string // chart: 类型 slug，见 service.ChartGenres
+	Interval string // chart: 好评百分比区间，如 100:90
+	Doulist  string // doulist: 豆列 ID
+}
+
+// Category mapping
+var categoryTagMap = map[string]categoryConfig{
+	"in_theaters": {Kind: categoryKindTag, Tag: "热门", Type: ""},
+	"hot_movies":  {Kind: categoryKindTag, Tag: "热门", Type: "movie"},
+	"hot_tv":      {Kind: categoryKindTag, Tag: "热门", Type: "tv"},
+	"us_tv":       {Kind: categoryKindTag, Tag: "美剧", Type: "tv"},
+	"jp_tv":       {Kind: categoryKindTag, Tag: "日剧", Type: "tv"},
+	"kr_tv":       {Kind: categoryKindTag, Tag: "韩剧", Type: "tv"},
+	"anime":       {Kind: categoryKindTag, Tag: "日本动画", Type: "tv"},
+	"documentary": {Kind: categoryKindTag, Tag: "纪录片", Type: "tv"},
+	"variety":     {Kind: categoryKindTag, Tag: "综艺", Type: "tv"},
+	"chinese_tv":  {Kind: categoryKindTag, Tag: "国产剧", Type: "tv"},
+
+	"chart_drama":     {Kind: categoryKindChart, Genre: "drama", Interval: service.DefaultChartInterval},
+	"chart_comedy":    {Kind: categoryKindChart, Genre: "comedy", Interval: service.DefaultChartInterval},
+	"chart_action":    {Kind: categoryKindChart, Genre: "action", Interval: service.DefaultChartInterval},
+	"chart_romance":   {Kind: categoryKindChart, Genre: "romance", Interval: service.DefaultChartInterval},
+	"chart_scifi":     {Kind: categoryKindChart, Genre: "scifi", Interval: service.DefaultChartInterval},
+	"chart_animation": {Kind: categoryKindChart, Genre: "animation", Interval: service.DefaultChartInterval},
+	"chart_mystery":   {Kind: categoryKindChart, Genre: "mystery", Interval: service.DefaultChartInterval},
+	"chart_crime":     {Kind: categoryKindChart, Genre: "crime", Interval: service.DefaultChartInterval},
+}
+
+// RegisterDoulistCategories adds doulists as named categories and returns
+// the IDs of the registered doulists. Names that collide with built-in
+// categories and invalid IDs are skipped.
+func RegisterDoulistCategories(categories map[string]string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for name, id := range categories {
+		if _, exists := categoryTagMap[name]; exists {
+			log.Warn().Str("category", name).Msg("豆列分类与已有分类重名，已跳过")
+			continue
+		}
+		if !model.IsDoubanID(id) {
+			log.Warn().Str("category", name).Str("id", id).Msg("无效的豆列 ID，已跳过")
+			continue
+		}
+		categoryTagMap[name] = categoryConfig{Kind: categoryKindDoulist, Doulist: id}
+		log.Info().Str("category", name).Str("doulist", id).Msg("📋 已注册豆列分类")
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 // CategoryHandler handles category API requests
 type CategoryHandler struct {
 	doubanService *service.DoubanService
 	cache         *repository.Cache
+	doulistTTL    time.Duration
 }
 
-// NewCategoryHandler creates a new CategoryHandler
-func NewCategoryHandler(douban *service.DoubanService, cache *repository.Cache) *CategoryHandler {
+// NewCategoryHandler creates a new CategoryHandler. doulistTTL is the cache
+// TTL of doulists fetched for doulist categories.
+func NewCategoryHandler(douban *service.DoubanService, cache *repository.Cache, doulistTTL time.Duration) *CategoryHandler {
 	return &CategoryHandler{
 		doubanService: douban,
 		cache:         cache,
+		doulistTTL:    doulistTTL,
 	}
 }
 
@@ -114,7 +166,7 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 		Msg("🔍 分页获取分类数据")
 
 	// Fetch data
-	data, err := h.doubanService.SearchSubjects(config.Type, config.Tag, limit, pageStart)
+	subjects, estimatedTotal, err := h.fetchCategory(config, limit, pageStart)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.APIResponse{
 			Code:  500,
@@ -123,12 +175,6 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 		return
 	}
 
-	subjects := data.Subjects
-	estimatedTotal := 100
-	if len(subjects) < limit {
-		estimatedTotal = pageStart + len(subjects)
-	}
-
 	log.Info().
 		Str("category", category).
 		Int("page", page).
@@ -159,6 +205,45 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	})
 }
 
+// fetchCategory fetches one page of a category and its (estimated) total
+func (h *CategoryHandler) fetchCategory(config categoryConfig, limit, start int) ([]model.Subject, int, error) {
+	if config.Kind == categoryKindDoulist {
+		doulist, err := cachedDoulist(context.Background(), h.cache, h.doubanService, config.Doulist, h.doulistTTL)
+		if err != nil {
+			return nil, 0, err
+		}
+		subjects := service.DoulistSubjects(doulist.Items)
+		return subjects[min(start, len(subjects)):min(start+limit, len(subjects))], len(subjects), nil
+	}
+
+	if config.Kind == categoryKindChart {
+		genre, ok := service.LookupChartGenre(config.Genre)
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown chart genre: %s", config.Genre)
+		}
+		page, err := h.doubanService.GetChart(genre.ID, config.Interval, start, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		total := cachedChartTotal(context.Background(), h.cache, h.doubanService, genre.ID, config.Interval)
+		if total == 0 {
+			total = start + len(page.Items)
+		}
+		return service.ChartSubjects(page.Items), total, nil
+	}
+
+	data, err := h.doubanService.SearchSubjects(config.Type, config.Tag, limit, start)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	estimatedTotal := 100
+	if len(data.Subjects) < limit {
+		estimatedTotal = start + len(data.Subjects)
+	}
+	return data.Subjects, estimatedTotal, nil
+}
+
 // DeleteCategoryCache clears all category cache
 // DELETE /api/v1/category
 func (h *CategoryHandler) DeleteCategoryCache(c *gin.Context) {
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const celebrityCacheKeyPrefix = "douban:celebrity:"
+
+// CelebrityHandler handles celebrity API requests
+type CelebrityHandler struct {
+	doubanService *service.DoubanService
+	cache         *repository.Cache
+}
+
+// NewCelebrityHandler creates a new CelebrityHandler
+func NewCelebrityHandler(douban *service.DoubanService, cache *repository.Cache) *CelebrityHandler {
+	return &CelebrityHandler{
+		doubanService: douban,
+		cache:         cache,
+	}
+}
+
+// GetCelebrity returns a celebrity profile
+// GET /api/v1/celebrity/:id
+func (h *CelebrityHandler) GetCelebrity(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的影人ID",
+		})
+		return
+	}
+
+	cacheKey := celebrityCacheKeyPrefix + id
+
+	// Check cache
+	var cachedData model.Celebrity
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   cachedData,
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Msg("🎭 获取影人信息")
+
+	celebrity, err := h.doubanService.GetCelebrity(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.APIResponse{
+			Code:  404,
+			Error: "未找到该影人信息",
+		})
+		return
+	}
+
+	h.cache.Set(ctx, cacheKey, celebrity)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   celebrity,
+		Source: "fresh",
+	})
+}
+
+// GetCelebrityWorks returns one page of a celebrity's filmography
+// GET /api/v1/celebrity/:id/works?page=1&sort=time|rating
+func (h *CelebrityHandler) GetCelebrityWorks(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的影人ID",
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+
+	sortBy := c.DefaultQuery("sort", service.CelebrityWorksSortTime)
+	if sortBy != service.CelebrityWorksSortTime && sortBy != service.CelebrityWorksSortRating {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "sort 只能为 time 或 rating",
+		})
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%sworks:%s:%s:page%d", celebrityCacheKeyPrefix, id, sortBy, page)
+
+	// Check cache
+	var cachedData model.CelebrityWorks
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   buildCelebrityWorksData(&cachedData, page, sortBy),
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Int("page", page).Str("sort", sortBy).Msg("🎞️ 获取影人作品")
+
+	works, err := h.doubanService.GetCelebrityWorks(id, page, sortBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.cache.Set(ctx, cacheKey, works)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   buildCelebrityWorksData(works, page, sortBy),
+		Source: "fresh",
+	})
+}
+
+// DeleteCelebrityCache clears celebrity cache
+// DELETE /api/v1/celebrity
+func (h *CelebrityHandler) DeleteCelebrityCache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.cache.DeletePattern(ctx, celebrityCacheKeyPrefix+"*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: fmt.Sprintf("影人缓存已清除 (%d 条)", deleted),
+	})
+}
+
+// buildCelebrityWorksData wraps a filmography page with pagination info
+func buildCelebrityWorksData(works *model.CelebrityWorks, page int, sortBy string) gin.H {
+	return gin.H{
+		"works": works.Works,
+		"sort":  sortBy,
+		"pagination": model.Pagination{
+			Page:    page,
+			Limit:   service.CelebrityWorksPageSize,
+			Total:   works.Total,
+			HasMore: page*service.CelebrityWorksPageSize < works.Total,
+		},
+	}
+}
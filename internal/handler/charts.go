@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const chartsCacheKeyPrefix = "douban:charts:"
+
+// ChartsHandler handles Douban genre chart API requests
+type ChartsHandler struct {
+	doubanService *service.DoubanService
+	cache         *repository.Cache
+}
+
+// NewChartsHandler creates a new ChartsHandler
+func NewChartsHandler(douban *service.DoubanService, cache *repository.Cache) *ChartsHandler {
+	return &ChartsHandler{
+		doubanService: douban,
+		cache:         cache,
+	}
+}
+
+// ListGenres returns the genres available for charts
+// GET /api/v1/charts
+func (h *ChartsHandler) ListGenres(c *gin.Context) {
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code: 200,
+		Data: service.ChartGenres,
+	})
+}
+
+// GetChart returns one page of a genre chart ranked by score percentage
+// GET /api/v1/charts/:genre?interval=100:90&page=1&limit=20
+func (h *ChartsHandler) GetChart(c *gin.Context) {
+	ctx := context.Background()
+
+	genre, ok := service.LookupChartGenre(c.Param("genre"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的类型",
+		})
+		return
+	}
+
+	interval := c.DefaultQuery("interval", service.DefaultChartInterval)
+	if !service.IsChartInterval(interval) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的区间，格式如 100:90",
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "页码必须大于0",
+		})
+		return
+	}
+	if limit < 1 || limit > 50 {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "每页数量必须在1-50之间",
+		})
+		return
+	}
+
+	pageStart := (page - 1) * limit
+	cacheKey := fmt.Sprintf("%s%d:%s:page%d:limit%d", chartsCacheKeyPrefix, genre.ID, interval, page, limit)
+
+	// Check cache
+	var cachedData model.ChartPage
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   buildChartData(&cachedData, genre, interval, page, limit),
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("genre", genre.Name).Str("interval", interval).Int("page", page).Msg("📊 获取类型排行榜")
+
+	chart, err := h.doubanService.GetChart(genre.ID, interval, pageStart, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+	chart.Total = cachedChartTotal(ctx, h.cache, h.doubanService, genre.ID, interval)
+
+	h.cache.Set(ctx, cacheKey, chart)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   buildChartData(chart, genre, interval, page, limit),
+		Source: "fresh",
+	})
+}
+
+// DeleteChartsCache clears chart cache
+// DELETE /api/v1/charts
+func (h *ChartsHandler) DeleteChartsCache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.cache.DeletePattern(ctx, chartsCacheKeyPrefix+"*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: fmt.Sprintf("排行榜缓存已清除 (%d 条)", deleted),
+	})
+}
+
+// cachedChartTotal returns the size of a chart interval, cached per genre and
+// interval so each chart page costs a single Douban request. Failed counts
+// (0) are not cached.
+func cachedChartTotal(ctx context.Context, cache *repository.Cache, douban *service.DoubanService, genreID int, interval string) int {
+	cacheKey := fmt.Sprintf("%stotal:%d:%s", chartsCacheKeyPrefix, genreID, interval)
+
+	var total int
+	if err := cache.Get(ctx, cacheKey, &total); err == nil {
+		return total
+	}
+
+	total = douban.GetChartTotal(genreID, interval)
+	if total > 0 {
+		cache.Set(ctx, cacheKey, total)
+	}
+	return total
+}
+
+// buildChartData wraps a chart page with its genre and pagination info
+func buildChartData(chart *model.ChartPage, genre service.ChartGenre, interval string, page, limit int) gin.H {
+	return gin.H{
+		"genre":    genre,
+		"interval": interval,
+		"items":    chart.Items,
+		"pagination": model.Pagination{
+			Page:    page,
+			Limit:   limit,
+			Total:   chart.Total,
+			HasMore: (page-1)*limit+len(chart.Items) < chart.Total,
+		},
+	}
+}
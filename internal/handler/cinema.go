@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const cinemaCacheKeyPrefix = "douban:cinema:"
+
+// CinemaHandler handles city-specific now playing and coming soon requests
+type CinemaHandler struct {
+	doubanService *service.DoubanService
+	cache         *repository.Cache
+	defaultCity   string
+}
+
+// NewCinemaHandler creates a new CinemaHandler
+func NewCinemaHandler(douban *service.DoubanService, cache *repository.Cache, defaultCity string) *CinemaHandler {
+	return &CinemaHandler{
+		doubanService: douban,
+		cache:         cache,
+		defaultCity:   defaultCity,
+	}
+}
+
+// GetNowPlaying returns the movies now playing in a city
+// GET /api/v1/cinema/nowplaying?city=beijing
+func (h *CinemaHandler) GetNowPlaying(c *gin.Context) {
+	city, ok := h.city(c)
+	if !ok {
+		return
+	}
+
+	movies, source, err := h.load(city, "nowplaying", h.doubanService.GetNowPlaying)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.respond(c, city, movies, source)
+}
+
+// GetComingSoon returns the movies coming soon in a city
+// GET /api/v1/cinema/coming?city=beijing
+func (h *CinemaHandler) GetComingSoon(c *gin.Context) {
+	city, ok := h.city(c)
+	if !ok {
+		return
+	}
+
+	movies, source, err := h.load(city, "coming", h.doubanService.GetComingSoon)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.respond(c, city, movies, source)
+}
+
+// GetComingSoonCalendar returns the coming soon movies as an iCalendar feed
+// with one all-day event per release date
+// GET /api/v1/cinema/coming.ics?city=beijing
+func (h *CinemaHandler) GetComingSoonCalendar(c *gin.Context) {
+	city, ok := h.city(c)
+	if !ok {
+		return
+	}
+
+	movies, source, err := h.load(city, "coming", h.doubanService.GetComingSoon)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+	if source == "redis-cache" {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buildCinemaCalendar(city, movies, time.Now()))
+}
+
+// DeleteCinemaCache clears cinema cache
+// DELETE /api/v1/cinema
+func (h *CinemaHandler) DeleteCinemaCache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.cache.DeletePattern(ctx, cinemaCacheKeyPrefix+"*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: fmt.Sprintf("院线缓存已清除 (%d 条)", deleted),
+	})
+}
+
+// city reads the city query parameter, writing a 400 response when invalid
+func (h *CinemaHandler) city(c *gin.Context) (string, bool) {
+	city := strings.ToLower(c.DefaultQuery("city", h.defaultCity))
+	if !service.IsCityName(city) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的城市，请使用拼音，如 beijing",
+		})
+		return "", false
+	}
+	return city, true
+}
+
+// load returns a cached list or fetches it, reporting the data source
+func (h *CinemaHandler) load(city, list string, fetch func(string) ([]model.CinemaMovie, error)) ([]model.CinemaMovie, string, error) {
+	ctx := context.Background()
+	cacheKey := cinemaCacheKeyPrefix + list + ":" + city
+
+	var cachedData []model.CinemaMovie
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		return cachedData, "redis-cache", nil
+	}
+
+	log.Info().Str("city", city).Str("list", list).Msg("🎟️ 获取院线影片")
+
+	movies, err := fetch(city)
+	if err != nil {
+		return nil, "", err
+	}
+
+	h.cache.Set(ctx, cacheKey, movies)
+
+	return movies, "fresh", nil
+}
+
+func (h *CinemaHandler) respond(c *gin.Context, city string, movies []model.CinemaMovie, source string) {
+	if source == "redis-cache" {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+	}
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code: 200,
+		Data: gin.H{
+			"city":   city,
+			"movies": movies,
+		},
+		Source: source,
+	})
+}
+
+// buildCinemaCalendar renders movies with a release date as all-day
+// VEVENTs (RFC 5545)
+func buildCinemaCalendar(city string, movies []model.CinemaMovie, now time.Time) []byte {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldICalLine(s))
+		b.WriteString("\r\n")
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//kerkerker-douban-service//cinema//ZH")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICalText("即将上映 · "+city))
+	line("X-WR-TIMEZONE:Asia/Shanghai")
+
+	for _, m := range movies {
+		date, err := time.Parse("2006-01-02", m.ReleaseDate)
+		if err != nil {
+			continue
+		}
+
+		var desc []string
+		if len(m.Genres) > 0 {
+			desc = append(desc, "类型: "+strings.Join(m.Genres, " / "))
+		}
+		if len(m.Regions) > 0 {
+			desc = append(desc, "地区: "+strings.Join(m.Regions, " / "))
+		}
+		if m.Wish > 0 {
+			desc = append(desc, fmt.Sprintf("%d人想看", m.Wish))
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:" + m.ID + "-" + city + "@movie.douban.com")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeICalText(m.Title))
+		if len(desc) > 0 {
+			line("DESCRIPTION:" + escapeICalText(strings.Join(desc, "\n")))
+		}
+		line("URL:" + m.URL)
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func escapeICalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// foldICalLine folds content lines longer than 75 octets without splitting
+// UTF-8 sequences
+func foldICalLine(s string) string {
+	const maxOctets = 75
+
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > maxOctets {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
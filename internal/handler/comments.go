@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// GetComments returns one page of short comments
+// GET /api/v1/detail/:id/comments?start=0&limit=20&sort=hot|new&status=watched|wish
+func (h *DetailHandler) GetComments(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣ID",
+		})
+		return
+	}
+
+	start, err := strconv.Atoi(c.DefaultQuery("start", "0"))
+	if err != nil || start < 0 {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "start 必须为非负整数",
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.CommentsMaxLimit)))
+	if limit < 1 || limit > service.CommentsMaxLimit {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: fmt.Sprintf("limit 必须在1-%d之间", service.CommentsMaxLimit),
+		})
+		return
+	}
+
+	sort := c.DefaultQuery("sort", service.CommentSortHot)
+	if sort != service.CommentSortHot && sort != service.CommentSortNew {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "sort 只能为 hot 或 new",
+		})
+		return
+	}
+
+	status := c.DefaultQuery("status", service.CommentStatusWatched)
+	if status != service.CommentStatusWatched && status != service.CommentStatusWish {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "status 只能为 watched 或 wish",
+		})
+		return
+	}
+
+	cacheKey := fmt.Sprintf("douban:detail:comments:%s:%s:%s:%d:%d", id, sort, status, start, limit)
+
+	// Check cache
+	var cachedData model.CommentsPage
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   buildCommentsData(&cachedData, start),
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Int("start", start).Str("sort", sort).Str("status", status).Msg("💬 获取短评")
+
+	page, err := h.doubanService.GetCommentsPage(id, start, limit, sort, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.cache.Set(ctx, cacheKey, page)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   buildCommentsData(page, start),
+		Source: "fresh",
+	})
+}
+
+// GetReviews returns one page of long-form reviews
+// GET /api/v1/detail/:id/reviews?start=0&sort=hot|new
+func (h *DetailHandler) GetReviews(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣ID",
+		})
+		return
+	}
+
+	start, err := strconv.Atoi(c.DefaultQuery("start", "0"))
+	if err != nil || start < 0 {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "start 必须为非负整数",
+		})
+		return
+	}
+
+	sort := c.DefaultQuery("sort", service.CommentSortHot)
+	if sort != service.CommentSortHot && sort != service.CommentSortNew {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "sort 只能为 hot 或 new",
+		})
+		return
+	}
+
+	cacheKey := fmt.Sprintf("douban:detail:reviews:%s:%s:%d", id, sort, start)
+
+	// Check cache
+	var cachedData model.ReviewsPage
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   buildReviewsData(&cachedData, start),
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Int("start", start).Str("sort", sort).Msg("📝 获取影评")
+
+	page, err := h.doubanService.GetReviews(id, start, sort)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.cache.Set(ctx, cacheKey, page)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   buildReviewsData(page, start),
+		Source: "fresh",
+	})
+}
+
+// GetReviewContent returns the full text of one review
+// GET /api/v1/detail/:id/reviews/:reviewId
+func (h *DetailHandler) GetReviewContent(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+	reviewID := c.Param("reviewId")
+
+	if !model.IsDoubanID(id) || !model.IsDoubanID(reviewID) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣ID",
+		})
+		return
+	}
+
+	// 与影评列表同属一个前缀，随条目缓存一并清除
+	cacheKey := fmt.Sprintf("douban:detail:reviews:%s:full:%s", id, reviewID)
+
+	// Check cache
+	var cachedData string
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   gin.H{"id": reviewID, "content": cachedData},
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	content, err := h.doubanService.GetReviewFullText(reviewID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.cache.Set(ctx, cacheKey, content)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   gin.H{"id": reviewID, "content": content},
+		Source: "fresh",
+	})
+}
+
+// buildCommentsData adds the offset of the next page to a comments page
+func buildCommentsData(page *model.CommentsPage, start int) gin.H {
+	return gin.H{
+		"comments":   page.Comments,
+		"total":      page.Total,
+		"has_more":   page.HasMore,
+		"next_start": nextStart(page.HasMore, start+len(page.Comments)),
+	}
+}
+
+// buildReviewsData adds the offset of the next page to a reviews page
+func buildReviewsData(page *model.ReviewsPage, start int) gin.H {
+	return gin.H{
+		"reviews":    page.Reviews,
+		"total":      page.Total,
+		"has_more":   page.HasMore,
+		"next_start": nextStart(page.HasMore, start+len(page.Reviews)),
+	}
+}
+
+// nextStart returns the start of the next page, or nil on the last page
+func nextStart(hasMore bool, start int) *int {
+	if !hasMore {
+		return nil
+	}
+	return &start
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -13,24 +15,58 @@ import (
 	"kerkerker-douban-service/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
+// extendedDetailFields maps the opt-in `fields` names to subject page values
+var extendedDetailFields = map[string]func(*model.SubjectPage) interface{}{
+	"synopsis":            func(p *model.SubjectPage) interface{} { return p.Synopsis },
+	"aliases":             func(p *model.SubjectPage) interface{} { return p.Aliases },
+	"genres":              func(p *model.SubjectPage) interface{} { return p.Genres },
+	"languages":           func(p *model.SubjectPage) interface{} { return p.Languages },
+	"countries":           func(p *model.SubjectPage) interface{} { return p.Countries },
+	"release_dates":       func(p *model.SubjectPage) interface{} { return p.ReleaseDates },
+	"imdb_id":             func(p *model.SubjectPage) interface{} { return p.IMDbID },
+	"votes":               func(p *model.SubjectPage) interface{} { return p.Votes },
+	"rating_distribution": func(p *model.SubjectPage) interface{} { return p.RatingDistribution },
+	"official_site":       func(p *model.SubjectPage) interface{} { return p.OfficialSite },
+	"writers":             func(p *model.SubjectPage) interface{} { return p.Writers },
+	"director_refs":       func(p *model.SubjectPage) interface{} { return p.Directors },
+	"actor_refs":          func(p *model.SubjectPage) interface{} { return p.Actors },
+}
+
+// detailIncludes are the opt-in `include` names, each enriching the detail
+// from the metadata providers
+var detailIncludes = map[string]bool{
+	"providers":    true, // 观看渠道
+	"credits":      true, // 演职员（角色、职位与头像）
+	"external_ids": true, // IMDb、TMDB 等外部 ID
+}
+
 // DetailHandler handles detail API requests
 type DetailHandler struct {
 	doubanService *service.DoubanService
+	metadata      *service.MetadataChain
 	cache         *repository.Cache
+	watchRegion   string
 }
 
-// NewDetailHandler creates a new DetailHandler
-func NewDetailHandler(douban *service.DoubanService, cache *repository.Cache) *DetailHandler {
+// NewDetailHandler creates a new DetailHandler. watchRegion is the default
+// region of watch providers.
+func NewDetailHandler(douban *service.DoubanService, metadata *service.MetadataChain, cache *repository.Cache, watchRegion string) *DetailHandler {
 	return &DetailHandler{
 		doubanService: douban,
+		metadata:      metadata,
 		cache:         cache,
+		watchRegion:   watchRegion,
 	}
 }
 
 // GetDetail returns movie/TV show details
-// GET /api/v1/detail/:id
+// GET /api/v1/detail/:id?fields=synopsis,imdb_id&include=providers&region=CN
+//
+// fields 为可选的扩展字段（解析豆瓣条目页面获得），传 all 返回全部扩展字段；
+// include 为可选的外部补充数据（providers、credits、external_ids），region 为观看渠道地区
 func (h *DetailHandler) GetDetail(c *gin.Context) {
 	ctx := context.Background()
 	id := c.Param("id")
@@ -43,18 +79,63 @@ func (h *DetailHandler) GetDetail(c *gin.Context) {
 		return
 	}
 
+	fields, err := parseDetailFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	includes, err := parseDetailIncludes(c.Query("include"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	// region 仅用于观看渠道
+	var region string
+	if includes["providers"] {
+		var ok bool
+		region, ok = service.NormalizeWatchRegion(c.DefaultQuery("region", h.watchRegion))
+		if !ok {
+			c.JSON(http.StatusBadRequest, model.APIResponse{
+				Code:  400,
+				Error: "无效的地区代码，应为 ISO 3166-1 两位字母（如 CN、US）",
+			})
+			return
+		}
+	}
+
 	cacheKey := "douban:detail:" + id
 
 	// Check cache
 	var cachedData model.SubjectDetail
 	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
 		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
-		c.JSON(http.StatusOK, buildDetailResponse(cachedData, "redis-cache"))
+		response := buildDetailResponse(cachedData, "redis-cache")
+		h.mergeExtendedFields(ctx, id, fields, response)
+		h.mergeCachedCelebrityRefs(ctx, id, response)
+		h.fillMissingText(ctx, cachedData, fields, response)
+		h.mergeIncludes(ctx, cachedData, includes, region, response)
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
 	// Get abstract
 	detail, err := h.doubanService.GetSubjectAbstract(id)
+	if errors.Is(err, service.ErrInvalidPayload) {
+		// 豆瓣返回结构变化，不应被客户端或 CDN 当作条目不存在
+		c.JSON(http.StatusBadGateway, model.APIResponse{
+			Code:  502,
+			Error: "豆瓣返回的数据格式异常",
+		})
+		return
+	}
 	if err != nil || detail.Subject == nil {
 		c.JSON(http.StatusNotFound, model.APIResponse{
 			Code:  404,
@@ -147,7 +228,221 @@ func (h *DetailHandler) GetDetail(c *gin.Context) {
 	// Cache result
 	h.cache.Set(ctx, cacheKey, detailData)
 
-	c.JSON(http.StatusOK, buildDetailResponse(detailData, "fresh"))
+	response := buildDetailResponse(detailData, "fresh")
+	h.mergeExtendedFields(ctx, id, fields, response)
+	h.mergeCachedCelebrityRefs(ctx, id, response)
+	h.fillMissingText(ctx, detailData, fields, response)
+	h.mergeIncludes(ctx, detailData, includes, region, response)
+	c.JSON(http.StatusOK, response)
+}
+
+// getSubjectPage returns the parsed subject page, cached separately from
+// the base detail so opting into extended fields never refetches the abstract
+func (h *DetailHandler) getSubjectPage(ctx context.Context, id string) (*model.SubjectPage, error) {
+	return cachedSubjectPage(ctx, h.cache, h.doubanService, id)
+}
+
+// cachedSubjectPage loads a subject page through the shared page cache
+func cachedSubjectPage(ctx context.Context, cache *repository.Cache, douban *service.DoubanService, id string) (*model.SubjectPage, error) {
+	cacheKey := "douban:detail:page:" + id
+
+	var cached model.SubjectPage
+	if err := cache.Get(ctx, cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	page, err := douban.GetSubjectPage(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Set(ctx, cacheKey, page)
+	return page, nil
+}
+
+// mergeExtendedFields adds the requested subject page fields to a detail response
+func (h *DetailHandler) mergeExtendedFields(ctx context.Context, id string, fields []string, response gin.H) {
+	if len(fields) == 0 {
+		return
+	}
+
+	page, err := h.getSubjectPage(ctx, id)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to fetch subject page")
+		return
+	}
+
+	for _, field := range fields {
+		response[field] = extendedDetailFields[field](page)
+	}
+}
+
+// mergeCachedCelebrityRefs adds director_refs and actor_refs when the
+// subject page is already cached, so celebrity IDs come for free without
+// a page request. They can be requested explicitly through `fields`.
+func (h *DetailHandler) mergeCachedCelebrityRefs(ctx context.Context, id string, response gin.H) {
+	_, hasDirectors := response["director_refs"]
+	_, hasActors := response["actor_refs"]
+	if hasDirectors && hasActors {
+		return
+	}
+
+	var page model.SubjectPage
+	if err := h.cache.Get(ctx, "douban:detail:page:"+id, &page); err != nil {
+		return
+	}
+	if !hasDirectors {
+		response["director_refs"] = page.Directors
+	}
+	if !hasActors {
+		response["actor_refs"] = page.Actors
+	}
+}
+
+// parseDetailFields validates the comma separated `fields` parameter
+func parseDetailFields(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range splitParam(raw) {
+		if field == "all" {
+			all := make([]string, 0, len(extendedDetailFields))
+			for name := range extendedDetailFields {
+				all = append(all, name)
+			}
+			sort.Strings(all)
+			return all, nil
+		}
+		if _, ok := extendedDetailFields[field]; !ok {
+			return nil, fmt.Errorf("无效的扩展字段: %s", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// parseDetailIncludes validates the comma separated `include` parameter
+func parseDetailIncludes(raw string) (map[string]bool, error) {
+	includes := make(map[string]bool)
+	for _, name := range splitParam(raw) {
+		if !detailIncludes[name] {
+			return nil, fmt.Errorf("无效的 include 参数: %s", name)
+		}
+		includes[name] = true
+	}
+	return includes, nil
+}
+
+// mergeIncludes adds the requested external metadata to a detail
+// response. Each included key is always present, and null when no provider
+// has data for the subject.
+func (h *DetailHandler) mergeIncludes(ctx context.Context, data model.SubjectDetail, includes map[string]bool, region string, response gin.H) {
+	if len(includes) == 0 {
+		return
+	}
+
+	subject := h.metadataSubject(ctx, data)
+	if includes["providers"] {
+		providers, _ := h.metadata.WatchProviders(ctx, subject, region)
+		response["watch_providers"] = providers
+	}
+	if includes["credits"] {
+		// 演职员对应优先使用条目页面中带影人 ID 的名单
+		if page, err := h.getSubjectPage(ctx, data.ID); err == nil {
+			subject.Directors = celebrityRefs(page.Directors, data.Directors)
+			subject.Actors = celebrityRefs(page.Actors, data.Actors)
+		}
+		credits, _ := h.metadata.Credits(ctx, subject)
+		response["credits"] = credits
+	}
+	if includes["external_ids"] {
+		ids, _ := h.metadata.ExternalIDs(ctx, subject)
+		response["external_ids"] = ids
+	}
+}
+
+// fillMissingText fills the requested synopsis and genres from the metadata
+// providers' localized text when Douban left them empty. Douban values are
+// never replaced, and providers are only asked when a requested field is
+// empty. Filled fields are listed in field_sources with their provider.
+func (h *DetailHandler) fillMissingText(ctx context.Context, data model.SubjectDetail, fields []string, response gin.H) {
+	var missing []string
+	for _, field := range fields {
+		switch value := response[field].(type) {
+		case string:
+			if field == "synopsis" && value == "" {
+				missing = append(missing, field)
+			}
+		case []string:
+			if field == "genres" && len(value) == 0 {
+				missing = append(missing, field)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	text, textSources := h.metadata.Text(ctx, h.metadataSubject(ctx, data))
+	sources := make(map[string]string)
+	for _, field := range missing {
+		switch {
+		case field == "synopsis" && text.Overview != "":
+			response[field] = text.Overview
+			sources[field] = textSources[service.MetadataFieldOverview]
+		case field == "genres" && len(text.Genres) > 0:
+			response[field] = text.Genres
+			sources[field] = textSources[service.MetadataFieldGenres]
+		}
+	}
+	if len(sources) > 0 {
+		response["field_sources"] = sources
+	}
+}
+
+// metadataSubject describes a detail for the metadata providers
+func (h *DetailHandler) metadataSubject(ctx context.Context, data model.SubjectDetail) service.MetadataSubject {
+	return service.MetadataSubject{
+		DoubanID:  data.ID,
+		Title:     data.Title,
+		Year:      data.ReleaseYear,
+		IsTV:      data.EpisodesCount != "",
+		Directors: celebrityRefs(nil, data.Directors),
+		Actors:    celebrityRefs(nil, data.Actors),
+		IMDbID: func() string {
+			page, err := h.getSubjectPage(ctx, data.ID)
+			if err != nil {
+				return ""
+			}
+			return page.IMDbID
+		},
+	}
+}
+
+// celebrityRefs prefers the page's linked people and falls back to the
+// abstract's plain names
+func celebrityRefs(refs []model.CelebrityRef, names []string) []model.CelebrityRef {
+	if len(refs) > 0 {
+		return refs
+	}
+	result := make([]model.CelebrityRef, len(names))
+	for i, name := range names {
+		result[i] = model.CelebrityRef{Name: name}
+	}
+	return result
+}
+
+// splitParam splits a comma separated query parameter
+func splitParam(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	return values
 }
 
 // DeleteDetailCache clears detail cache
@@ -166,6 +461,13 @@ func (h *DetailHandler) DeleteDetailCache(c *gin.Context) {
 
 	cacheKey := "douban:detail:" + id
 	h.cache.Delete(ctx, cacheKey)
+	h.cache.Delete(ctx, "douban:detail:page:"+id)
+	h.cache.Delete(ctx, "douban:detail:episodes:"+id)
+	h.cache.Delete(ctx, "douban:detail:episodes:"+id+":details")
+	h.cache.Delete(ctx, "douban:detail:videos:"+id)
+	h.cache.DeletePattern(ctx, "douban:detail:comments:"+id+":*")
+	h.cache.DeletePattern(ctx, "douban:detail:reviews:"+id+":*")
+	h.cache.DeletePattern(ctx, photosCacheKeyPrefix+id+":*")
 
 	c.JSON(http.StatusOK, model.APIResponse{
 		Code:    200,
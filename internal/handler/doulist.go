@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const doulistCacheKeyPrefix = "douban:doulist:"
+
+// DoulistHandler handles Douban doulist (豆列) API requests
+type DoulistHandler struct {
+	doubanService *service.DoubanService
+	cache         *repository.Cache
+	cacheTTL      time.Duration
+	refreshIDs    []string
+	crawling      sync.Map // 正在后台抓取的豆列 ID
+}
+
+// NewDoulistHandler creates a new DoulistHandler. refreshIDs are the
+// doulists kept warm by Refresh.
+func NewDoulistHandler(douban *service.DoubanService, cache *repository.Cache, cacheTTL time.Duration, refreshIDs []string) *DoulistHandler {
+	return &DoulistHandler{
+		doubanService: douban,
+		cache:         cache,
+		cacheTTL:      cacheTTL,
+		refreshIDs:    refreshIDs,
+	}
+}
+
+// GetDoulist returns a doulist with its items in list order
+// GET /api/v1/doulist/:id
+//
+// 未缓存时只抓取首页：已注册的豆列在后台抓取全部页面后写入缓存，
+// 其他豆列只缓存首页
+func (h *DoulistHandler) GetDoulist(c *gin.Context) {
+	ctx := context.Background()
+
+	id := c.Param("id")
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆列 ID",
+		})
+		return
+	}
+
+	// Check cache
+	var cachedData model.Doulist
+	if err := h.cache.Get(ctx, doulistCacheKey(id), &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   cachedData,
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Msg("📋 获取豆列")
+
+	doulist, err := h.doubanService.GetDoulist(id, 1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	// 已注册豆列的完整内容由后台抓取后写入缓存
+	if doulist.Truncated && slices.Contains(h.refreshIDs, id) {
+		h.crawlInBackground(id)
+	} else {
+		h.cache.Set(ctx, doulistCacheKey(id), doulist, h.cacheTTL)
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   doulist,
+		Source: "fresh",
+	})
+}
+
+// Refresh re-fetches the registered doulists and overwrites the cache.
+// Doulists that fail keep their previous cached value.
+func (h *DoulistHandler) Refresh(ctx context.Context) error {
+	failed := 0
+	for i, id := range h.refreshIDs {
+		if i > 0 {
+			// 逐个抓取，避免短时间内集中请求
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(service.DoulistFetchDelay):
+			}
+		}
+
+		doulist, err := h.doubanService.GetDoulist(id, service.MaxDoulistPages)
+		if err != nil {
+			log.Warn().Err(err).Str("id", id).Msg("Failed to refresh doulist")
+			failed++
+			continue
+		}
+		h.cache.Set(ctx, doulistCacheKey(id), doulist, h.cacheTTL)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d/%d doulists failed", failed, len(h.refreshIDs))
+	}
+	return nil
+}
+
+// crawlInBackground fetches all pages of a registered doulist and caches
+// it. At most one crawl runs per doulist.
+func (h *DoulistHandler) crawlInBackground(id string) {
+	if _, running := h.crawling.LoadOrStore(id, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer h.crawling.Delete(id)
+
+		doulist, err := h.doubanService.GetDoulist(id, service.MaxDoulistPages)
+		if err != nil {
+			log.Warn().Err(err).Str("id", id).Msg("Failed to crawl doulist")
+			return
+		}
+		h.cache.Set(context.Background(), doulistCacheKey(id), doulist, h.cacheTTL)
+	}()
+}
+
+// DeleteDoulistCache clears doulist cache
+// DELETE /api/v1/doulist
+func (h *DoulistHandler) DeleteDoulistCache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.cache.DeletePattern(ctx, doulistCacheKeyPrefix+"*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: fmt.Sprintf("豆列缓存已清除 (%d 条)", deleted),
+	})
+}
+
+func doulistCacheKey(id string) string {
+	return doulistCacheKeyPrefix + id
+}
+
+// cachedDoulist returns a doulist from cache, fetching and caching it for
+// ttl on a miss
+func cachedDoulist(ctx context.Context, cache *repository.Cache, douban *service.DoubanService, id string, ttl time.Duration) (*model.Doulist, error) {
+	var doulist model.Doulist
+	if err := cache.Get(ctx, doulistCacheKey(id), &doulist); err == nil {
+		return &doulist, nil
+	}
+
+	fresh, err := douban.GetDoulist(id, service.MaxDoulistPages)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(ctx, doulistCacheKey(id), fresh, ttl)
+	return fresh, nil
+}
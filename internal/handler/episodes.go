@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// EpisodesHandler handles TV episode API requests
+type EpisodesHandler struct {
+	doubanService *service.DoubanService
+	tmdbService   *service.TMDBService
+	matcher       *service.TMDBMatcher
+	cache         *repository.Cache
+}
+
+// NewEpisodesHandler creates a new EpisodesHandler
+func NewEpisodesHandler(douban *service.DoubanService, tmdb *service.TMDBService, matcher *service.TMDBMatcher, cache *repository.Cache) *EpisodesHandler {
+	return &EpisodesHandler{
+		doubanService: douban,
+		tmdbService:   tmdb,
+		matcher:       matcher,
+		cache:         cache,
+	}
+}
+
+// GetEpisodes returns the episodes, seasons and air schedule of a TV subject
+// GET /api/v1/detail/:id/episodes?details=1
+//
+// details 为可选参数，开启后逐个抓取前若干集的分集页面（标题、播出时间、简介）
+func (h *EpisodesHandler) GetEpisodes(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣ID",
+		})
+		return
+	}
+
+	details, _ := strconv.ParseBool(c.DefaultQuery("details", "false"))
+
+	cacheKey := "douban:detail:episodes:" + id
+	if details {
+		cacheKey += ":details"
+	}
+
+	// Check cache
+	var cachedData model.EpisodeListing
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		service.UpdateSchedule(&cachedData, time.Now())
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   cachedData,
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Bool("details", details).Msg("📺 获取分集信息")
+
+	// 复用详情的条目页面缓存
+	page, err := cachedSubjectPage(ctx, h.cache, h.doubanService, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	listing := h.doubanService.GetEpisodes(page, details)
+
+	h.mergeTMDBEpisodes(ctx, listing)
+
+	h.cache.Set(ctx, cacheKey, listing)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   listing,
+		Source: "fresh",
+	})
+}
+
+// mergeTMDBEpisodes fills stills, overviews and missing fields from TMDB when
+// the subject's IMDb ID maps to a TMDB show. Douban values take precedence.
+func (h *EpisodesHandler) mergeTMDBEpisodes(ctx context.Context, listing *model.EpisodeListing) {
+	if !h.tmdbService.IsConfigured() {
+		return
+	}
+
+	match, err := h.matcher.Match(ctx, service.MatchQuery{
+		DoubanID: listing.SubjectID,
+		IsTV:     true,
+		IMDbID:   func() string { return listing.IMDbID },
+	})
+	if err != nil || match == nil || match.MediaType != "tv" {
+		return
+	}
+	tvID := match.TMDBID
+
+	tmdbEpisodes, err := h.tmdbService.GetSeasonEpisodes(tvID, listing.SeasonNumber)
+	if err != nil {
+		log.Warn().Err(err).Int("tmdb", tvID).Int("season", listing.SeasonNumber).Msg("TMDB season fetch failed")
+		return
+	}
+	listing.TMDBID = tvID
+
+	byNumber := make(map[int]int, len(listing.Episodes))
+	for i, ep := range listing.Episodes {
+		byNumber[ep.Number] = i
+	}
+
+	for _, te := range tmdbEpisodes {
+		i, ok := byNumber[te.EpisodeNumber]
+		if !ok {
+			listing.Episodes = append(listing.Episodes, model.Episode{Number: te.EpisodeNumber})
+			i = len(listing.Episodes) - 1
+			byNumber[te.EpisodeNumber] = i
+		}
+
+		ep := &listing.Episodes[i]
+		ep.Still = h.tmdbService.ImageURL(te.StillPath)
+		if ep.Title == "" {
+			ep.Title = te.Name
+		}
+		if ep.Overview == "" {
+			ep.Overview = te.Overview
+		}
+		if ep.AirDate == "" {
+			ep.AirDate = te.AirDate
+		}
+		if ep.Runtime == 0 {
+			ep.Runtime = te.Runtime
+		}
+	}
+
+	sort.Slice(listing.Episodes, func(i, j int) bool {
+		return listing.Episodes[i].Number < listing.Episodes[j].Number
+	})
+	service.UpdateSchedule(listing, time.Now())
+}
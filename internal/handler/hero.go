@@ -21,21 +21,26 @@ import (
 const heroDataCacheKey = "douban:hero:movies"
 const defaultRequestTimeout = 30 * time.Second
 
+// heroTrailerCandidates is how many trailers are tried for a playable source
+const heroTrailerCandidates = 3
+
 // HeroHandler handles Hero Banner API requests
 type HeroHandler struct {
 	doubanService *service.DoubanService
-	tmdbService   *service.TMDBService
+	metadata      *service.MetadataChain
 	cache         *repository.Cache
 	cacheTTL      time.Duration
+	withTrailer   bool // 是否附带预告片
 }
 
 // NewHeroHandler creates a new HeroHandler
-func NewHeroHandler(douban *service.DoubanService, tmdb *service.TMDBService, cache *repository.Cache, cacheTTL time.Duration) *HeroHandler {
+func NewHeroHandler(douban *service.DoubanService, metadata *service.MetadataChain, cache *repository.Cache, cacheTTL time.Duration, withTrailer bool) *HeroHandler {
 	return &HeroHandler{
 		doubanService: douban,
-		tmdbService:   tmdb,
+		metadata:      metadata,
 		cache:         cache,
 		cacheTTL:      cacheTTL,
+		withTrailer:   withTrailer,
 	}
 }
 
@@ -111,54 +116,103 @@ func (h *HeroHandler) GetHero(c *gin.Context) {
 			var genres []string
 			var description string
 			var releaseYear string
+			isTV := m.EpisodeInfo != "" // 列表中带更新集数的为剧集
 
-			// 使用 channel 接收详情结果，实现超时控制
-			detailDone := make(chan struct{})
+			// 使用带缓冲的 channel 接收结果，超时后 goroutine 仍可写入并退出
+			detailChan := make(chan *model.DoubanAbstractResponse, 1)
 			go func() {
-				if detail, err := h.doubanService.GetSubjectAbstract(m.ID); err == nil && detail.Subject != nil {
+				detail, err := h.doubanService.GetSubjectAbstract(m.ID)
+				if err != nil {
+					detail = nil
+				}
+				detailChan <- detail
+			}()
+
+			select {
+			case detail := <-detailChan:
+				if detail != nil && detail.Subject != nil {
 					genres = detail.Subject.Types
 					releaseYear = detail.Subject.ReleaseYear
+					isTV = isTV || service.IsTVAbstract(detail.Subject)
 					if detail.Subject.ShortComment != nil {
 						description = detail.Subject.ShortComment.Content
 					}
 				}
-				close(detailDone)
-			}()
+			case <-movieCtx.Done():
+				log.Debug().Str("title", m.Title).Msg("⏱️ 获取详情超时")
+			}
+
+			// Get backdrop and localized text from the metadata providers
+			var extras heroExtras
+			extrasChan := make(chan heroExtras, 1)
+			go func(year string, isTV bool) {
+				extrasChan <- h.heroMetadata(movieCtx, m, year, isTV)
+			}(releaseYear, isTV)
 
 			select {
-			case <-detailDone:
-				// 详情获取成功
+			case extras = <-extrasChan:
+				// 外部元数据获取完成
 			case <-movieCtx.Done():
-				log.Debug().Str("title", m.Title).Msg("⏱️ 获取详情超时")
+				log.Debug().Str("title", m.Title).Msg("⏱️ 获取外部元数据超时")
 			}
 
-			// Get TMDB backdrop
-			var backdropURL string
-			if h.tmdbService.IsConfigured() {
-				tmdbDone := make(chan struct{})
+			// Get trailer (optional)
+			var trailer *model.Video
+			if h.withTrailer {
+				trailerChan := make(chan *model.Video, 1)
 				go func() {
-					backdropURL, _ = h.tmdbService.SearchMovieBackdrop(m.Title, releaseYear)
-					close(tmdbDone)
+					video, err := h.doubanService.GetPlayableTrailer(m.ID, heroTrailerCandidates)
+					if err != nil {
+						video = nil
+					}
+					trailerChan <- video
 				}()
 
 				select {
-				case <-tmdbDone:
-					// TMDB 请求完成
+				case trailer = <-trailerChan:
+					// 预告片获取完成
 				case <-movieCtx.Done():
-					log.Debug().Str("title", m.Title).Msg("⏱️ TMDB 请求超时")
+					log.Debug().Str("title", m.Title).Msg("⏱️ 获取预告片超时")
 				}
 			}
 
 			// Convert cover to high quality
 			cover := getHighQualityPoster(m.Cover)
 
+			// 豆瓣字段优先，缺失时使用外部元数据补全，并记录来源
+			sources := make(map[string]string)
+
 			// 优化3: 降级策略 - 无 backdrop 时使用封面
-			posterHorizontal := backdropURL
+			posterHorizontal := extras.backdrop
+			sources["poster_horizontal"] = extras.backdropSource
 			if posterHorizontal == "" {
 				posterHorizontal = cover // 使用封面作为备选
+				sources["poster_horizontal"] = "douban"
 				log.Debug().Str("title", m.Title).Msg("📸 使用封面作为横幅备选")
 			}
 
+			var tagline string
+			if text := extras.text; text != nil {
+				if description == "" && text.Overview != "" {
+					description = text.Overview
+					sources["description"] = extras.textSources[service.MetadataFieldOverview]
+				}
+				if len(genres) == 0 && len(text.Genres) > 0 {
+					genres = text.Genres
+					sources["genres"] = extras.textSources[service.MetadataFieldGenres]
+				}
+				if text.Tagline != "" {
+					tagline = text.Tagline
+					sources["tagline"] = extras.textSources[service.MetadataFieldTagline]
+				}
+			}
+			if _, ok := sources["description"]; !ok && description != "" {
+				sources["description"] = "douban"
+			}
+			if _, ok := sources["genres"]; !ok && len(genres) > 0 {
+				sources["genres"] = "douban"
+			}
+
 			hero := &model.HeroMovie{
 				ID:               m.ID,
 				Title:            m.Title,
@@ -170,6 +224,10 @@ func (h *HeroHandler) GetHero(c *gin.Context) {
 				EpisodeInfo:      m.EpisodeInfo,
 				Genres:           genres,
 				Description:      description,
+				Tagline:          tagline,
+				Trailer:          trailer,
+				Images:           extras.images,
+				FieldSources:     sources,
 			}
 
 			resultChan <- heroResult{index: index, hero: hero}
@@ -196,9 +254,13 @@ func (h *HeroHandler) GetHero(c *gin.Context) {
 		}
 	}
 
-	// Cache the result
+	// Cache the result; trailer sources are signed and expire sooner
 	if len(heroMovies) > 0 {
-		h.cache.Set(ctx, heroDataCacheKey, heroMovies, h.cacheTTL)
+		ttl := h.cacheTTL
+		if h.withTrailer {
+			ttl = min(ttl, service.TrailerSourceTTL)
+		}
+		h.cache.Set(ctx, heroDataCacheKey, heroMovies, ttl)
 	}
 
 	log.Info().Int("count", len(heroMovies)).Msg("✅ Hero Banner 数据获取成功")
@@ -222,6 +284,62 @@ func (h *HeroHandler) DeleteHeroCache(c *gin.Context) {
 	})
 }
 
+// heroExtras is the external metadata of a hero subject
+type heroExtras struct {
+	backdrop       string
+	backdropSource string
+	images         *model.HeroImages
+	text           *model.LocalizedText
+	textSources    map[string]string
+}
+
+// heroMetadata returns the backdrop URL of a hero subject, the size
+// variants of its top backdrop, poster and logo, and its localized text
+// from the metadata providers. The IMDb ID is read from the subject page
+// only when needed.
+func (h *HeroHandler) heroMetadata(ctx context.Context, m model.Subject, year string, isTV bool) heroExtras {
+	subject := service.MetadataSubject{
+		DoubanID: m.ID,
+		Title:    m.Title,
+		Year:     year,
+		IsTV:     isTV,
+		IMDbID: func() string {
+			page, err := cachedSubjectPage(ctx, h.cache, h.doubanService, m.ID)
+			if err != nil {
+				return ""
+			}
+			return page.IMDbID
+		},
+	}
+
+	// poster_horizontal 保持原图，客户端可按需从 images 选择尺寸
+	artwork, backdrop, backdropSource := h.metadata.ImagesAndBackdrop(ctx, subject)
+
+	var images *model.HeroImages
+	if artwork != nil {
+		first := func(images []model.ArtworkImage) []model.ImageVariant {
+			if len(images) == 0 {
+				return nil
+			}
+			return images[0].Variants
+		}
+		images = &model.HeroImages{
+			Backdrop: first(artwork.Backdrops),
+			Poster:   first(artwork.Posters),
+			Logo:     first(artwork.Logos),
+		}
+	}
+
+	text, textSources := h.metadata.Text(ctx, subject)
+	return heroExtras{
+		backdrop:       backdrop,
+		backdropSource: backdropSource,
+		images:         images,
+		text:           text,
+		textSources:    textSources,
+	}
+}
+
 // getHighQualityPoster converts Douban small poster to large
 func getHighQualityPoster(url string) string {
 	if url == "" {
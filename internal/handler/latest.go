@@ -2,8 +2,10 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"sync"
+	"sync/atomic"
 
 	"kerkerker-douban-service/internal/model"
 	"kerkerker-douban-service/internal/repository"
@@ -69,6 +71,7 @@ func (h *LatestHandler) GetLatest(c *gin.Context) {
 
 	results := make([]fetchResult, len(categories))
 	var wg sync.WaitGroup
+	var invalidPayload atomic.Bool
 
 	for i, cat := range categories {
 		wg.Add(1)
@@ -80,6 +83,9 @@ func (h *LatestHandler) GetLatest(c *gin.Context) {
 			defer wg.Done()
 			data, err := h.doubanService.SearchSubjects(c.typ, c.tag, 24, 0)
 			if err != nil {
+				if errors.Is(err, service.ErrInvalidPayload) {
+					invalidPayload.Store(true)
+				}
 				log.Warn().Err(err).Str("tag", c.tag).Msg("Failed to fetch")
 				results[idx] = fetchResult{name: c.name, data: []model.Subject{}}
 				return
@@ -102,8 +108,10 @@ func (h *LatestHandler) GetLatest(c *gin.Context) {
 		totalItems += len(r.data)
 	}
 
-	// Cache result (30 minutes)
-	h.cache.Set(ctx, latestCacheKey, resultData)
+	// Cache result (30 minutes) - 上游结构异常时不缓存
+	if !invalidPayload.Load() {
+		h.cache.Set(ctx, latestCacheKey, resultData)
+	}
 
 	log.Info().Msg("✅ 最新内容数据获取成功")
 
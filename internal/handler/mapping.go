@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultLowConfidence is the confidence below which matches are listed for review
+const defaultLowConfidence = 0.6
+
+// MappingHandler handles admin requests for Douban-to-TMDB ID mappings
+type MappingHandler struct {
+	tmdbService *service.TMDBService
+	mappings    *repository.MappingStore
+	cache       *repository.Cache
+}
+
+// NewMappingHandler creates a new MappingHandler
+func NewMappingHandler(tmdb *service.TMDBService, mappings *repository.MappingStore, cache *repository.Cache) *MappingHandler {
+	return &MappingHandler{
+		tmdbService: tmdb,
+		mappings:    mappings,
+		cache:       cache,
+	}
+}
+
+// ListMappings returns the stored matches below a confidence, lowest first
+// GET /api/v1/tmdb/mappings?max_confidence=0.6
+func (h *MappingHandler) ListMappings(c *gin.Context) {
+	ctx := context.Background()
+
+	maxConfidence, err := strconv.ParseFloat(c.DefaultQuery("max_confidence", strconv.FormatFloat(defaultLowConfidence, 'f', -1, 64)), 64)
+	if err != nil || maxConfidence <= 0 {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "max_confidence 必须为正数",
+		})
+		return
+	}
+
+	mappings, err := h.mappings.List(ctx, maxConfidence)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code: 200,
+		Data: mappings,
+	})
+}
+
+// SetMapping stores a manual override for a Douban subject
+// PUT /api/v1/tmdb/mappings/:id  {"tmdb_id": 278, "media_type": "movie"}
+func (h *MappingHandler) SetMapping(c *gin.Context) {
+	ctx := context.Background()
+
+	id := c.Param("id")
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣 ID",
+		})
+		return
+	}
+
+	var body struct {
+		TMDBID    int    `json:"tmdb_id"`
+		MediaType string `json:"media_type"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的请求体",
+		})
+		return
+	}
+	if body.TMDBID <= 0 || (body.MediaType != "movie" && body.MediaType != "tv") {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "tmdb_id 必须为正整数，media_type 必须是 movie 或 tv",
+		})
+		return
+	}
+
+	mapping := &model.TMDBMapping{
+		DoubanID:   id,
+		TMDBID:     body.TMDBID,
+		MediaType:  body.MediaType,
+		Confidence: 1,
+		Method:     model.MatchMethodManual,
+	}
+
+	// 配置了 TMDB 时校验条目存在，并记录 TMDB 标题
+	if h.tmdbService.IsConfigured() {
+		title, err := h.tmdbService.LookupTitle(service.TMDBMatch{ID: body.TMDBID, MediaType: body.MediaType})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.APIResponse{
+				Code:  400,
+				Error: fmt.Sprintf("无法获取 TMDB 条目: %v", err),
+			})
+			return
+		}
+		mapping.MatchedTitle = title.Title
+	}
+
+	if err := h.mappings.Set(ctx, mapping); err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Int("tmdb_id", body.TMDBID).Str("media_type", body.MediaType).Msg("🔗 已设置 TMDB 手动映射")
+	h.invalidate(ctx, id)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Data:    mapping,
+		Message: "映射已保存",
+	})
+}
+
+// DeleteMapping clears the mapping of a Douban subject so that the next
+// lookup searches again
+// DELETE /api/v1/tmdb/mappings/:id
+func (h *MappingHandler) DeleteMapping(c *gin.Context) {
+	ctx := context.Background()
+
+	id := c.Param("id")
+	existed, err := h.mappings.Delete(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+	if !existed {
+		c.JSON(http.StatusNotFound, model.APIResponse{
+			Code:  404,
+			Error: "映射不存在",
+		})
+		return
+	}
+	h.invalidate(ctx, id)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: "映射已清除",
+	})
+}
+
+// invalidate clears the cached responses built from a subject's mapping:
+// the hero banner and the subject's artwork
+func (h *MappingHandler) invalidate(ctx context.Context, id string) {
+	h.cache.Delete(ctx, heroDataCacheKey)
+	h.cache.Delete(ctx, artworkCacheKeyPrefix+id)
+}
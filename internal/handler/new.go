@@ -2,11 +2,13 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"kerkerker-douban-service/internal/model"
 	"kerkerker-douban-service/internal/repository"
@@ -129,6 +131,7 @@ func (h *NewHandler) GetNew(c *gin.Context) {
 
 	results := make([]model.CategoryData, len(categories))
 	var wg sync.WaitGroup
+	var invalidPayload atomic.Bool
 
 	for i, cat := range categories {
 		wg.Add(1)
@@ -140,6 +143,9 @@ func (h *NewHandler) GetNew(c *gin.Context) {
 			defer wg.Done()
 			data, err := h.doubanService.SearchSubjects(c.typ, c.tag, 24, 0)
 			if err != nil {
+				if errors.Is(err, service.ErrInvalidPayload) {
+					invalidPayload.Store(true)
+				}
 				results[idx] = model.CategoryData{Name: c.name, Data: []model.Subject{}}
 				return
 			}
@@ -150,8 +156,10 @@ func (h *NewHandler) GetNew(c *gin.Context) {
 	wg.Wait()
 	resultData = results
 
-	// Cache result
-	h.cache.Set(ctx, cacheKey, resultData)
+	// Cache result - 上游结构异常时不缓存
+	if !invalidPayload.Load() {
+		h.cache.Set(ctx, cacheKey, resultData)
+	}
 
 	totalItems := 0
 	for _, r := range resultData {
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const photosCacheKeyPrefix = "douban:photos:"
+
+// GetPhotos returns a window of a subject's photo gallery
+// GET /api/v1/detail/:id/photos?type=still|poster|wallpaper&start=0&count=30
+func (h *DetailHandler) GetPhotos(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣ID",
+		})
+		return
+	}
+
+	photoType := c.DefaultQuery("type", service.PhotoTypeStill)
+	if !service.IsPhotoType(photoType) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "type 只能为 still、poster 或 wallpaper",
+		})
+		return
+	}
+
+	start, err := strconv.Atoi(c.DefaultQuery("start", "0"))
+	if err != nil || start < 0 {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "start 必须为非负整数",
+		})
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(service.PhotosPageSize)))
+	if err != nil || count < 1 || count > service.PhotosPageSize {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: fmt.Sprintf("count 必须在1-%d之间", service.PhotosPageSize),
+		})
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%s%s:%s:%d:%d", photosCacheKeyPrefix, id, photoType, start, count)
+
+	// Check cache
+	var cachedData model.PhotosPage
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   cachedData,
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Str("type", photoType).Int("start", start).Int("count", count).Msg("🖼️ 获取剧照")
+
+	page, err := h.doubanService.GetPhotoGallery(id, photoType, start, count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.cache.Set(ctx, cacheKey, page)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   page,
+		Source: "fresh",
+	})
+}
+
+// DeletePhotosCache clears photo gallery cache
+// DELETE /api/v1/photos
+func (h *DetailHandler) DeletePhotosCache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.cache.DeletePattern(ctx, photosCacheKeyPrefix+"*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: fmt.Sprintf("剧照缓存已清除 (%d 条)", deleted),
+	})
+}
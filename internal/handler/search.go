@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -70,6 +71,7 @@ func (h *SearchHandler) Search(c *gin.Context) {
 
 	var suggestResult []model.SuggestItem
 	var advancedResult []model.Subject
+	var advancedErr error
 
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -101,7 +103,7 @@ func (h *SearchHandler) Search(c *gin.Context) {
 			if typ == "tv" {
 				tags = "电视剧"
 			}
-			advancedResult, _ = h.doubanService.AdvancedSearch(tags, sort, genres, yearRange, start, limit)
+			advancedResult, advancedErr = h.doubanService.AdvancedSearch(tags, sort, genres, yearRange, start, limit)
 		}
 	}()
 
@@ -112,8 +114,10 @@ func (h *SearchHandler) Search(c *gin.Context) {
 		Advanced: advancedResult,
 	}
 
-	// Cache result
-	h.cache.Set(ctx, cacheKey, result)
+	// Cache result - 上游结构异常时不缓存
+	if !errors.Is(advancedErr, service.ErrInvalidPayload) {
+		h.cache.Set(ctx, cacheKey, result)
+	}
 
 	log.Info().
 		Int("suggest", len(suggestResult)).
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TMDBKeyHandler handles admin requests for TMDB API key management.
+// Key values are never returned, only their ID and a masked form.
+type TMDBKeyHandler struct {
+	tmdbService *service.TMDBService
+}
+
+// NewTMDBKeyHandler creates a new TMDBKeyHandler
+func NewTMDBKeyHandler(tmdb *service.TMDBService) *TMDBKeyHandler {
+	return &TMDBKeyHandler{tmdbService: tmdb}
+}
+
+// ListKeys returns the health of every configured key
+// GET /api/v1/tmdb/keys
+func (h *TMDBKeyHandler) ListKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code: 200,
+		Data: h.tmdbService.KeyStatus(),
+	})
+}
+
+// AddKey adds a key at runtime. It is not persisted across restarts.
+// POST /api/v1/tmdb/keys  {"key": "eyJhbGciOi..."}
+func (h *TMDBKeyHandler) AddKey(c *gin.Context) {
+	var body struct {
+		Key string `json:"key"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的请求体",
+		})
+		return
+	}
+
+	status, added := h.tmdbService.AddKey(body.Key)
+	if status == nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "key 不能为空",
+		})
+		return
+	}
+	if !added {
+		c.JSON(http.StatusConflict, model.APIResponse{
+			Code:  409,
+			Data:  status,
+			Error: "该 Key 已存在",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Data:    status,
+		Message: "Key 已添加，重启后需在 TMDB_API_KEY 中配置才会保留",
+	})
+}
+
+// RemoveKey removes a key by ID
+// DELETE /api/v1/tmdb/keys/:id
+func (h *TMDBKeyHandler) RemoveKey(c *gin.Context) {
+	if !h.tmdbService.RemoveKey(c.Param("id")) {
+		c.JSON(http.StatusNotFound, model.APIResponse{
+			Code:  404,
+			Error: "Key 不存在",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: "Key 已移除",
+	})
+}
+
+// TestKey probes TMDB with one key and returns its updated health
+// POST /api/v1/tmdb/keys/:id/test
+func (h *TMDBKeyHandler) TestKey(c *gin.Context) {
+	status, err := h.tmdbService.TestKey(c.Param("id"))
+	if errors.Is(err, service.ErrKeyNotFound) {
+		c.JSON(http.StatusNotFound, model.APIResponse{
+			Code:  404,
+			Error: "Key 不存在",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:    200,
+			Data:    status,
+			Message: fmt.Sprintf("Key 测试失败: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Data:    status,
+		Message: "Key 可用",
+	})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const top250CacheKeyPrefix = "douban:top250:"
+
+// Top250Handler handles Douban Top 250 API requests
+type Top250Handler struct {
+	doubanService *service.DoubanService
+	cache         *repository.Cache
+	cacheTTL      time.Duration
+}
+
+// NewTop250Handler creates a new Top250Handler
+func NewTop250Handler(douban *service.DoubanService, cache *repository.Cache, cacheTTL time.Duration) *Top250Handler {
+	return &Top250Handler{
+		doubanService: douban,
+		cache:         cache,
+		cacheTTL:      cacheTTL,
+	}
+}
+
+// GetTop250 returns one page of the Douban Top 250 list
+// GET /api/v1/top250?page=1
+func (h *Top250Handler) GetTop250(c *gin.Context) {
+	ctx := context.Background()
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 || page > service.Top250Pages {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: fmt.Sprintf("页码必须在1-%d之间", service.Top250Pages),
+		})
+		return
+	}
+
+	cacheKey := top250CacheKey(page)
+
+	// Check cache
+	var cachedData []model.Top250Item
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   buildTop250Data(cachedData, page),
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Int("page", page).Msg("🏆 获取豆瓣 Top 250")
+
+	items, err := h.doubanService.GetTop250(page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	h.cache.Set(ctx, cacheKey, items, h.cacheTTL)
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   buildTop250Data(items, page),
+		Source: "fresh",
+	})
+}
+
+// Refresh re-fetches every Top 250 page and overwrites the cache.
+// Pages that fail keep their previous cached value.
+func (h *Top250Handler) Refresh(ctx context.Context) error {
+	failed := 0
+	for page := 1; page <= service.Top250Pages; page++ {
+		if page > 1 {
+			// 逐页抓取，避免短时间内集中请求
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(2 * time.Second):
+			}
+		}
+
+		items, err := h.doubanService.GetTop250(page)
+		if err != nil {
+			log.Warn().Err(err).Int("page", page).Msg("Failed to refresh top250 page")
+			failed++
+			continue
+		}
+		h.cache.Set(ctx, top250CacheKey(page), items, h.cacheTTL)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d/%d top250 pages failed", failed, service.Top250Pages)
+	}
+	return nil
+}
+
+// DeleteTop250Cache clears Top 250 cache
+// DELETE /api/v1/top250
+func (h *Top250Handler) DeleteTop250Cache(c *gin.Context) {
+	ctx := context.Background()
+
+	deleted, err := h.cache.DeletePattern(ctx, top250CacheKeyPrefix+"*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:    200,
+		Message: fmt.Sprintf("Top 250 缓存已清除 (%d 条)", deleted),
+	})
+}
+
+func top250CacheKey(page int) string {
+	return fmt.Sprintf("%spage%d", top250CacheKeyPrefix, page)
+}
+
+// buildTop250Data wraps a Top 250 page with pagination info
+func buildTop250Data(items []model.Top250Item, page int) gin.H {
+	return gin.H{
+		"subjects": items,
+		"pagination": model.Pagination{
+			Page:    page,
+			Limit:   service.Top250PageSize,
+			Total:   service.Top250PageSize * service.Top250Pages,
+			HasMore: page < service.Top250Pages,
+		},
+	}
+}
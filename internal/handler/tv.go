@@ -2,8 +2,10 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"sync"
+	"sync/atomic"
 
 	"kerkerker-douban-service/internal/model"
 	"kerkerker-douban-service/internal/repository"
@@ -64,6 +66,7 @@ func (h *TVHandler) GetTV(c *gin.Context) {
 
 	results := make([]model.CategoryData, len(categories))
 	var wg sync.WaitGroup
+	var invalidPayload atomic.Bool
 
 	for i, cat := range categories {
 		wg.Add(1)
@@ -71,6 +74,9 @@ func (h *TVHandler) GetTV(c *gin.Context) {
 			defer wg.Done()
 			data, err := h.doubanService.SearchSubjects("tv", tag, 24, 0)
 			if err != nil {
+				if errors.Is(err, service.ErrInvalidPayload) {
+					invalidPayload.Store(true)
+				}
 				log.Warn().Err(err).Str("tag", tag).Msg("Failed to fetch TV")
 				results[idx] = model.CategoryData{Name: name, Data: []model.Subject{}}
 				return
@@ -82,8 +88,10 @@ func (h *TVHandler) GetTV(c *gin.Context) {
 
 	wg.Wait()
 
-	// Cache result (1 hour)
-	h.cache.Set(ctx, tvCacheKey, results)
+	// Cache result (1 hour) - 上游结构异常时不缓存，避免空数据被长期返回
+	if !invalidPayload.Load() {
+		h.cache.Set(ctx, tvCacheKey, results)
+	}
 
 	totalItems := 0
 	for _, r := range results {
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+	"kerkerker-douban-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// videoSourcesToResolve is how many Douban videos get a playable source
+const videoSourcesToResolve = 5
+
+// VideosHandler handles trailer and clip API requests
+type VideosHandler struct {
+	doubanService *service.DoubanService
+	tmdbService   *service.TMDBService
+	matcher       *service.TMDBMatcher
+	cache         *repository.Cache
+}
+
+// NewVideosHandler creates a new VideosHandler
+func NewVideosHandler(douban *service.DoubanService, tmdb *service.TMDBService, matcher *service.TMDBMatcher, cache *repository.Cache) *VideosHandler {
+	return &VideosHandler{
+		doubanService: douban,
+		tmdbService:   tmdb,
+		matcher:       matcher,
+		cache:         cache,
+	}
+}
+
+// GetVideos returns trailers and clips from Douban and TMDB
+// GET /api/v1/detail/:id/videos
+func (h *VideosHandler) GetVideos(c *gin.Context) {
+	ctx := context.Background()
+	id := c.Param("id")
+
+	if !model.IsDoubanID(id) {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Code:  400,
+			Error: "无效的豆瓣ID",
+		})
+		return
+	}
+
+	cacheKey := "douban:detail:videos:" + id
+
+	// Check cache
+	var cachedData []model.Video
+	if err := h.cache.Get(ctx, cacheKey, &cachedData); err == nil {
+		c.Set("cache_source", "redis-cache") // 标记缓存命中供 metrics 追踪
+		c.JSON(http.StatusOK, model.APIResponse{
+			Code:   200,
+			Data:   cachedData,
+			Source: "redis-cache",
+		})
+		return
+	}
+
+	log.Info().Str("id", id).Msg("🎥 获取预告片")
+
+	videos, err := h.doubanService.GetTrailers(id, videoSourcesToResolve)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to fetch Douban trailers")
+		videos = []model.Video{}
+	}
+	doubanErr := err
+
+	tmdbVideos := h.getTMDBVideos(ctx, id)
+	if err != nil && tmdbVideos == nil {
+		c.JSON(http.StatusInternalServerError, model.APIResponse{
+			Code:  500,
+			Error: err.Error(),
+		})
+		return
+	}
+	videos = append(videos, tmdbVideos...)
+
+	// 豆瓣失败时只返回 TMDB 结果，不缓存残缺的列表；播放地址带有时效签名，按其有效期缓存
+	if doubanErr == nil {
+		h.cache.Set(ctx, cacheKey, videos, service.TrailerSourceTTL)
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Code:   200,
+		Data:   videos,
+		Source: "fresh",
+	})
+}
+
+// getTMDBVideos looks up the subject on TMDB through its stored mapping or
+// IMDb ID. Returns nil when TMDB is not configured or the subject can't be
+// matched.
+func (h *VideosHandler) getTMDBVideos(ctx context.Context, id string) []model.Video {
+	if !h.tmdbService.IsConfigured() {
+		return nil
+	}
+
+	mapping, err := h.matcher.Match(ctx, service.MatchQuery{
+		DoubanID: id,
+		IMDbID: func() string {
+			page, err := cachedSubjectPage(ctx, h.cache, h.doubanService, id)
+			if err != nil {
+				return ""
+			}
+			return page.IMDbID
+		},
+	})
+	if err != nil || mapping == nil {
+		return nil
+	}
+
+	videos, err := h.tmdbService.GetVideos(service.TMDBMatch{ID: mapping.TMDBID, MediaType: mapping.MediaType})
+	if err != nil {
+		log.Warn().Err(err).Int("tmdb", mapping.TMDBID).Msg("TMDB videos fetch failed")
+		return nil
+	}
+	return videos
+}
@@ -45,18 +45,374 @@ type SubjectDetail struct {
 	Recommendations []Subject `json:"recommendations,omitempty"`
 }
 
+// SubjectPage holds the extended fields parsed from a Douban subject page
+// (https://movie.douban.com/subject/<id>/), including its JSON-LD block
+type SubjectPage struct {
+	ID                 string             `json:"id"`
+	Title              string             `json:"title,omitempty"` // 中文标题，如 "权力的游戏 第二季"
+	Synopsis           string             `json:"synopsis,omitempty"`
+	Aliases            []string           `json:"aliases,omitempty"`
+	Genres             []string           `json:"genres,omitempty"`
+	Languages          []string           `json:"languages,omitempty"`
+	Countries          []string           `json:"countries,omitempty"`
+	ReleaseDates       []ReleaseDate      `json:"release_dates,omitempty"`
+	IMDbID             string             `json:"imdb_id,omitempty"`
+	Votes              int                `json:"votes,omitempty"`
+	RatingDistribution map[string]float64 `json:"rating_distribution,omitempty"` // "5".."1" 星占比（%）
+	OfficialSite       string             `json:"official_site,omitempty"`
+	Writers            []string           `json:"writers,omitempty"`
+	Directors          []CelebrityRef     `json:"directors,omitempty"`
+	Actors             []CelebrityRef     `json:"actors,omitempty"`
+
+	// 剧集信息，供分集列表使用
+	EpisodesCount   int      `json:"episodes_count,omitempty"`
+	EpisodeDuration string   `json:"episode_duration,omitempty"`
+	EpisodeNumbers  []int    `json:"episode_numbers,omitempty"` // 有分集页面的集数
+	Seasons         []Season `json:"seasons,omitempty"`
+}
+
+// ReleaseDate is a release (or premiere) date in one region
+type ReleaseDate struct {
+	Date   string `json:"date"`
+	Region string `json:"region,omitempty"`
+}
+
 // HeroMovie is a movie for the hero banner
 type HeroMovie struct {
-	ID               string   `json:"id"`
-	Title            string   `json:"title"`
-	Rate             string   `json:"rate"`
-	Cover            string   `json:"cover"`
-	PosterHorizontal string   `json:"poster_horizontal"`
-	PosterVertical   string   `json:"poster_vertical"`
-	URL              string   `json:"url"`
-	EpisodeInfo      string   `json:"episode_info,omitempty"`
-	Genres           []string `json:"genres,omitempty"`
-	Description      string   `json:"description,omitempty"`
+	ID               string      `json:"id"`
+	Title            string      `json:"title"`
+	Rate             string      `json:"rate"`
+	Cover            string      `json:"cover"`
+	PosterHorizontal string      `json:"poster_horizontal"`
+	PosterVertical   string      `json:"poster_vertical"`
+	URL              string      `json:"url"`
+	EpisodeInfo      string      `json:"episode_info,omitempty"`
+	Genres           []string    `json:"genres,omitempty"`
+	Description      string      `json:"description,omitempty"`
+	Tagline          string      `json:"tagline,omitempty"`
+	Trailer          *Video      `json:"trailer,omitempty"`
+	Images           *HeroImages `json:"images,omitempty"` // TMDB 多尺寸图片，用于响应式加载
+
+	// FieldSources records where description, tagline, genres and
+	// poster_horizontal came from: "douban" or a metadata provider name
+	FieldSources map[string]string `json:"field_sources,omitempty"`
+}
+
+// HeroImages holds the size variants of a hero's TMDB artwork
+type HeroImages struct {
+	Backdrop []ImageVariant `json:"backdrop,omitempty"`
+	Poster   []ImageVariant `json:"poster,omitempty"`
+	Logo     []ImageVariant `json:"logo,omitempty"`
+}
+
+// ImageVariant is one size of an image. Width is 0 for height-based sizes
+// whose width is unknown.
+type ImageVariant struct {
+	Size  string `json:"size"` // TMDB 尺寸，如 w780 / original
+	Width int    `json:"width,omitempty"`
+	URL   string `json:"url"`
+}
+
+// ArtworkImage is a TMDB image with every configured size
+type ArtworkImage struct {
+	Language    string         `json:"language,omitempty"` // 空为无文字
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	AspectRatio float64        `json:"aspect_ratio"`
+	VoteAverage float64        `json:"vote_average"`
+	Variants    []ImageVariant `json:"variants"`
+}
+
+// Artwork is the TMDB artwork of a subject, ordered by language preference
+type Artwork struct {
+	DoubanID  string         `json:"douban_id"`
+	TMDBID    int            `json:"tmdb_id"`
+	MediaType string         `json:"media_type"`
+	Backdrops []ArtworkImage `json:"backdrops"`
+	Posters   []ArtworkImage `json:"posters"`
+	Logos     []ArtworkImage `json:"logos"`
+}
+
+// WatchProvider is a streaming service, store or rental service
+type WatchProvider struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Logo string `json:"logo,omitempty"`
+}
+
+// WatchProviders lists where a title can be watched in one region. Link is
+// the TMDB watch page that deep links to each provider.
+type WatchProviders struct {
+	Region    string          `json:"region"`
+	Link      string          `json:"link"`
+	Streaming []WatchProvider `json:"streaming"`
+	Rent      []WatchProvider `json:"rent"`
+	Buy       []WatchProvider `json:"buy"`
+}
+
+// CreditPerson is a cast or crew member reconciled between Douban and TMDB.
+// Match is how the two were paired ("name" or "position"), empty when the
+// person comes from one source only.
+type CreditPerson struct {
+	DoubanID   string `json:"douban_id,omitempty"`
+	TMDBID     int    `json:"tmdb_id,omitempty"`
+	Name       string `json:"name"`
+	TMDBName   string `json:"tmdb_name,omitempty"`
+	Character  string `json:"character,omitempty"`
+	Job        string `json:"job,omitempty"`
+	Department string `json:"department"`
+	Order      int    `json:"order"`
+	Profile    string `json:"profile,omitempty"`
+	Match      string `json:"match,omitempty"`
+}
+
+// Credits is the merged cast and crew of a subject
+type Credits struct {
+	Cast []CreditPerson `json:"cast"`
+	Crew []CreditPerson `json:"crew"`
+}
+
+// LocalizedText is the localized overview, tagline and genres of a subject
+type LocalizedText struct {
+	Overview string   `json:"overview,omitempty"`
+	Tagline  string   `json:"tagline,omitempty"`
+	Genres   []string `json:"genres,omitempty"`
+}
+
+// ExternalIDs are the IDs of a subject on other sites
+type ExternalIDs struct {
+	DoubanID   string `json:"douban_id"`
+	IMDbID     string `json:"imdb_id,omitempty"`
+	TMDBID     int    `json:"tmdb_id,omitempty"`
+	MediaType  string `json:"media_type,omitempty"` // TMDB 类型 movie / tv
+	TVDBID     int    `json:"tvdb_id,omitempty"`
+	WikidataID string `json:"wikidata_id,omitempty"`
+	Facebook   string `json:"facebook,omitempty"`
+	Instagram  string `json:"instagram,omitempty"`
+	Twitter    string `json:"twitter,omitempty"`
+}
+
+// Fill copies the IDs that are empty in e from other
+func (e *ExternalIDs) Fill(other *ExternalIDs) {
+	if e.IMDbID == "" {
+		e.IMDbID = other.IMDbID
+	}
+	if e.TMDBID == 0 {
+		e.TMDBID, e.MediaType = other.TMDBID, other.MediaType
+	}
+	if e.TVDBID == 0 {
+		e.TVDBID = other.TVDBID
+	}
+	if e.WikidataID == "" {
+		e.WikidataID = other.WikidataID
+	}
+	if e.Facebook == "" {
+		e.Facebook = other.Facebook
+	}
+	if e.Instagram == "" {
+		e.Instagram = other.Instagram
+	}
+	if e.Twitter == "" {
+		e.Twitter = other.Twitter
+	}
+}
+
+// TMDB match methods
+const (
+	MatchMethodIMDb   = "imdb"   // IMDb 编号精确匹配（TMDB /find）
+	MatchMethodTitle  = "title"  // 标题与年份模糊搜索
+	MatchMethodManual = "manual" // 管理员手动指定
+)
+
+// TMDBMapping links a Douban subject to a TMDB title. Confidence is in
+// [0, 1]; IMDb matches and manual overrides are always 1.
+type TMDBMapping struct {
+	DoubanID     string  `json:"douban_id"`
+	TMDBID       int     `json:"tmdb_id"`
+	MediaType    string  `json:"media_type"` // movie / tv
+	Confidence   float64 `json:"confidence"`
+	Method       string  `json:"method"`
+	IMDbID       string  `json:"imdb_id,omitempty"`
+	Title        string  `json:"title,omitempty"`         // 豆瓣标题
+	MatchedTitle string  `json:"matched_title,omitempty"` // TMDB 标题
+	UpdatedAt    int64   `json:"updated_at"`
+}
+
+// Video is a trailer or clip of a subject
+type Video struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`               // trailer / clip / featurette / other
+	Duration    int    `json:"duration,omitempty"` // 秒
+	Cover       string `json:"cover,omitempty"`
+	URL         string `json:"url"`              // 视频页面
+	Source      string `json:"source,omitempty"` // 可直接播放的地址（豆瓣 mp4）
+	Provider    string `json:"provider"`         // douban / tmdb
+	Site        string `json:"site,omitempty"`   // TMDB 视频托管站点，如 YouTube
+	Language    string `json:"language,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+// Top250Item is an entry of the Douban Top 250 list
+type Top250Item struct {
+	Rank          int      `json:"rank"`
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title,omitempty"`
+	OtherTitles   []string `json:"other_titles,omitempty"`
+	Cover         string   `json:"cover"`
+	URL           string   `json:"url"`
+	Rate          string   `json:"rate"`
+	Votes         int      `json:"votes"`
+	Year          string   `json:"year"`
+	Region        string   `json:"region"`
+	Genres        []string `json:"genres,omitempty"`
+	Credits       string   `json:"credits,omitempty"`
+	Quote         string   `json:"quote,omitempty"`
+}
+
+// EpisodeListing holds the episodes, seasons and air schedule of a TV subject
+type EpisodeListing struct {
+	SubjectID    string          `json:"subject_id"`
+	SeasonNumber int             `json:"season_number"`
+	Seasons      []Season        `json:"seasons,omitempty"`
+	Episodes     []Episode       `json:"episodes"`
+	Schedule     EpisodeSchedule `json:"schedule"`
+	IMDbID       string          `json:"imdb_id,omitempty"`
+	TMDBID       int             `json:"tmdb_id,omitempty"`
+}
+
+// Episode is a single TV episode
+type Episode struct {
+	Number        int    `json:"number"`
+	Title         string `json:"title,omitempty"`
+	OriginalTitle string `json:"original_title,omitempty"`
+	AirDate       string `json:"air_date,omitempty"`
+	Overview      string `json:"overview,omitempty"`
+	Still         string `json:"still,omitempty"` // 来自 TMDB
+	Runtime       int    `json:"runtime,omitempty"`
+}
+
+// Season links one season of a show to its Douban subject
+type Season struct {
+	ID      string `json:"id"`
+	Number  int    `json:"number"`
+	Current bool   `json:"current,omitempty"`
+}
+
+// EpisodeSchedule summarises when a show airs
+type EpisodeSchedule struct {
+	Premiere        []ReleaseDate `json:"premiere,omitempty"`
+	EpisodesCount   int           `json:"episodes_count,omitempty"`
+	EpisodeDuration string        `json:"episode_duration,omitempty"`
+	Aired           int           `json:"aired"`
+	NextEpisode     *Episode      `json:"next_episode,omitempty"`
+}
+
+// CelebrityRef links a credited name to its Douban celebrity ID.
+// ID is empty when the page lists the person without a link.
+type CelebrityRef struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// Celebrity is a person profile parsed from a Douban celebrity page
+type Celebrity struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	NameEn        string   `json:"name_en,omitempty"`
+	OtherNames    []string `json:"other_names,omitempty"`
+	Photo         string   `json:"photo,omitempty"`
+	Gender        string   `json:"gender,omitempty"`
+	Constellation string   `json:"constellation,omitempty"`
+	BirthDate     string   `json:"birth_date,omitempty"`
+	BirthPlace    string   `json:"birth_place,omitempty"`
+	DeathDate     string   `json:"death_date,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	IMDbID        string   `json:"imdb_id,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	URL           string   `json:"url"`
+}
+
+// CelebrityWork is an entry of a celebrity's filmography
+type CelebrityWork struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Cover string   `json:"cover,omitempty"`
+	URL   string   `json:"url"`
+	Year  string   `json:"year,omitempty"`
+	Rate  string   `json:"rate,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// CelebrityWorks is one page of a celebrity's filmography
+type CelebrityWorks struct {
+	Works []CelebrityWork `json:"works"`
+	Total int             `json:"total"`
+}
+
+// ChartItem is an entry of a Douban genre chart
+type ChartItem struct {
+	Rank        int      `json:"rank"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Cover       string   `json:"cover"`
+	URL         string   `json:"url"`
+	Rate        string   `json:"rate"`
+	Votes       int      `json:"votes"`
+	Types       []string `json:"types,omitempty"`
+	Regions     []string `json:"regions,omitempty"`
+	ReleaseDate string   `json:"release_date,omitempty"`
+	Actors      []string `json:"actors,omitempty"`
+}
+
+// ChartPage is one page of a Douban genre chart
+type ChartPage struct {
+	Items []ChartItem `json:"items"`
+	Total int         `json:"total"`
+}
+
+// Doulist is a curated Douban list (豆列) with its items in list order
+type Doulist struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Author      string        `json:"author,omitempty"`
+	URL         string        `json:"url"`
+	Total       int           `json:"total"`
+	Items       []DoulistItem `json:"items"`
+	Truncated   bool          `json:"truncated,omitempty"` // 仅包含前若干页
+	UpdatedAt   string        `json:"updated_at,omitempty"`
+}
+
+// DoulistItem is an entry of a doulist. Comment is the curator's note.
+type DoulistItem struct {
+	Position int    `json:"position"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Cover    string `json:"cover,omitempty"`
+	URL      string `json:"url"`
+	Rate     string `json:"rate,omitempty"`
+	Abstract string `json:"abstract,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// CinemaMovie is a movie now playing or coming soon in a city
+type CinemaMovie struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Cover       string   `json:"cover"`
+	URL         string   `json:"url"`
+	Rate        string   `json:"rate,omitempty"`
+	Votes       int      `json:"votes,omitempty"`
+	Year        string   `json:"year,omitempty"`
+	ReleaseDate string   `json:"release_date,omitempty"` // YYYY-MM-DD
+	Duration    string   `json:"duration,omitempty"`
+	Regions     []string `json:"regions,omitempty"`
+	Genres      []string `json:"genres,omitempty"`
+	Directors   []string `json:"directors,omitempty"`
+	Actors      []string `json:"actors,omitempty"`
+	Wish        int      `json:"wish,omitempty"` // 想看人数
 }
 
 // CategoryData holds data for a category
@@ -67,24 +423,77 @@ type CategoryData struct {
 
 // Photo represents a photo from Douban
 type Photo struct {
-	ID    string `json:"id"`
-	Image string `json:"image"`
-	Thumb string `json:"thumb"`
+	ID          string      `json:"id"`
+	Image       string      `json:"image"`
+	Thumb       string      `json:"thumb"`
+	Sizes       *PhotoSizes `json:"sizes,omitempty"`
+	Width       int         `json:"width,omitempty"`
+	Height      int         `json:"height,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+// PhotoSizes holds the size variants Douban serves for one photo
+type PhotoSizes struct {
+	Thumb  string `json:"thumb"`
+	Medium string `json:"medium"`
+	Large  string `json:"large"`
+	Raw    string `json:"raw"`
+}
+
+// PhotosPage is one page of a subject's photo gallery
+type PhotosPage struct {
+	Photos  []Photo `json:"photos"`
+	Total   int     `json:"total"`
+	HasMore bool    `json:"has_more"`
 }
 
 // Comment represents a comment
 type Comment struct {
-	ID      string        `json:"id,omitempty"`
-	Content string        `json:"content"`
-	Author  CommentAuthor `json:"author"`
+	ID        string        `json:"id,omitempty"`
+	Content   string        `json:"content"`
+	Author    CommentAuthor `json:"author"`
+	Rating    int           `json:"rating,omitempty"` // 1-5 星，0 表示未评分
+	Status    string        `json:"status,omitempty"` // 看过 / 想看
+	CreatedAt string        `json:"created_at,omitempty"`
+	Location  string        `json:"location,omitempty"`
+	Votes     int           `json:"votes,omitempty"`
 }
 
 // CommentAuthor represents the author of a comment
 type CommentAuthor struct {
+	ID     string `json:"id,omitempty"`
 	Name   string `json:"name"`
 	Avatar string `json:"avatar,omitempty"`
 }
 
+// CommentsPage is one page of short comments
+type CommentsPage struct {
+	Comments []Comment `json:"comments"`
+	Total    int       `json:"total"`
+	HasMore  bool      `json:"has_more"`
+}
+
+// Review is a long-form review
+type Review struct {
+	ID           string        `json:"id"`
+	Title        string        `json:"title"`
+	Summary      string        `json:"summary"`
+	Author       CommentAuthor `json:"author"`
+	Rating       int           `json:"rating,omitempty"` // 1-5 星，0 表示未评分
+	CreatedAt    string        `json:"created_at,omitempty"`
+	UsefulCount  int           `json:"useful_count"`
+	UselessCount int           `json:"useless_count"`
+	ReplyCount   int           `json:"reply_count"`
+	URL          string        `json:"url"`
+}
+
+// ReviewsPage is one page of long-form reviews
+type ReviewsPage struct {
+	Reviews []Review `json:"reviews"`
+	Total   int      `json:"total"`
+	HasMore bool     `json:"has_more"`
+}
+
 // Pagination holds pagination information
 type Pagination struct {
 	Page    int  `json:"page"`
@@ -120,6 +529,34 @@ type DoubanSearchResponse struct {
 	Subjects []Subject `json:"subjects"`
 }
 
+// DoubanAdvancedSearchResponse is the response from Douban new_search_subjects API
+type DoubanAdvancedSearchResponse struct {
+	Data []Subject `json:"data"`
+}
+
+// DoubanChartItem is an entry of the Douban chart top_list API
+type DoubanChartItem struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	CoverURL    string   `json:"cover_url"`
+	URL         string   `json:"url"`
+	Score       string   `json:"score"`
+	VoteCount   int      `json:"vote_count"`
+	Rank        int      `json:"rank"`
+	Types       []string `json:"types"`
+	Regions     []string `json:"regions"`
+	ReleaseDate string   `json:"release_date"`
+	Actors      []string `json:"actors"`
+}
+
+// DoubanChartResponse is the response from Douban chart top_list API
+type DoubanChartResponse []DoubanChartItem
+
+// DoubanChartCountResponse is the response from Douban chart top_list_count API
+type DoubanChartCountResponse struct {
+	Total int `json:"total"`
+}
+
 // DoubanAbstractResponse is the response from Douban abstract API
 type DoubanAbstractResponse struct {
 	Subject *DoubanAbstractSubject `json:"subject"`
@@ -138,6 +575,7 @@ type DoubanAbstractSubject struct {
 	Duration      string   `json:"duration"`
 	Region        string   `json:"region"`
 	EpisodesCount string   `json:"episodes_count"`
+	IsTV          bool     `json:"is_tv"`
 	ShortComment  *struct {
 		Content string `json:"content"`
 		Author  string `json:"author"`
@@ -170,6 +608,12 @@ type DoubanCommentsResponse struct {
 	Comments []DoubanComment `json:"comments"`
 }
 
+// DoubanReviewFull is the response from Douban review full text API
+type DoubanReviewFull struct {
+	Body string `json:"body"`
+	HTML string `json:"html"`
+}
+
 // DoubanRecommendation is a recommendation from Douban API
 type DoubanRecommendation struct {
 	ID    string `json:"id"`
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxValidationProblems caps how many problems are reported per payload
+const maxValidationProblems = 5
+
+// ValidationError is returned when a decoded upstream payload does not
+// match the shape we expect (schema drift)
+type ValidationError struct {
+	Endpoint string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s payload: %s", e.Endpoint, strings.Join(e.Problems, "; "))
+}
+
+// validator collects validation problems for a single payload
+type validator struct {
+	endpoint string
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	if len(v.problems) < maxValidationProblems {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Endpoint: v.endpoint, Problems: v.problems}
+}
+
+// checkSubject validates the fields every list subject must carry
+func (v *validator) checkSubject(field string, s Subject) {
+	if !IsDoubanID(s.ID) {
+		v.addf("%s.id: invalid format %q", field, s.ID)
+	}
+	if strings.TrimSpace(s.Title) == "" {
+		v.addf("%s.title: missing", field)
+	}
+	if !hasHostSuffix(s.URL, "douban.com") {
+		v.addf("%s.url: unexpected host %q", field, s.URL)
+	}
+	if s.Cover != "" && !hasHostSuffix(s.Cover, "doubanio.com") {
+		v.addf("%s.cover: unexpected host %q", field, s.Cover)
+	}
+}
+
+// Validate checks a decoded search_subjects payload
+func (r *DoubanSearchResponse) Validate() error {
+	v := &validator{endpoint: "search_subjects"}
+	if r.Subjects == nil {
+		v.addf("subjects: missing")
+	}
+	for i, s := range r.Subjects {
+		v.checkSubject(fmt.Sprintf("subjects[%d]", i), s)
+	}
+	return v.err()
+}
+
+// Validate checks a decoded new_search_subjects payload
+func (r *DoubanAdvancedSearchResponse) Validate() error {
+	v := &validator{endpoint: "new_search_subjects"}
+	if r.Data == nil {
+		v.addf("data: missing")
+	}
+	for i, s := range r.Data {
+		v.checkSubject(fmt.Sprintf("data[%d]", i), s)
+	}
+	return v.err()
+}
+
+// Validate checks a decoded subject_abstract payload
+func (r *DoubanAbstractResponse) Validate() error {
+	v := &validator{endpoint: "subject_abstract"}
+	if r.Subject == nil {
+		v.addf("subject: missing")
+		return v.err()
+	}
+	if !IsDoubanID(r.Subject.ID) {
+		v.addf("subject.id: invalid format %q", r.Subject.ID)
+	}
+	if strings.TrimSpace(r.Subject.Title) == "" {
+		v.addf("subject.title: missing")
+	}
+	if !hasHostSuffix(r.Subject.URL, "douban.com") {
+		v.addf("subject.url: unexpected host %q", r.Subject.URL)
+	}
+	return v.err()
+}
+
+// Validate checks a decoded chart top_list payload
+func (r *DoubanChartResponse) Validate() error {
+	v := &validator{endpoint: "chart_top_list"}
+	if *r == nil {
+		v.addf("items: missing")
+	}
+	for i, item := range *r {
+		v.checkSubject(fmt.Sprintf("items[%d]", i), Subject{
+			ID:    item.ID,
+			Title: item.Title,
+			URL:   item.URL,
+			Cover: item.CoverURL,
+		})
+	}
+	return v.err()
+}
+
+// IsDoubanID reports whether s looks like a Douban subject ID
+func IsDoubanID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// hasHostSuffix reports whether rawURL is an absolute http(s) URL on domain
+// or one of its subdomains
+func hasHostSuffix(rawURL, domain string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := u.Hostname()
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tmdbMappingKey is the Redis hash of Douban subject ID -> TMDB mapping JSON
+const tmdbMappingKey = "tmdb:mapping"
+
+// MappingStore persists Douban-to-TMDB ID mappings in Redis. Unlike cache
+// entries, mappings never expire.
+type MappingStore struct {
+	client *redis.Client
+}
+
+// NewMappingStore creates a new MappingStore
+func NewMappingStore(redisURL string) (*MappingStore, error) {
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opt)
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &MappingStore{client: client}, nil
+}
+
+// Get returns the mapping of a Douban subject, or nil if none is stored
+func (s *MappingStore) Get(ctx context.Context, doubanID string) (*model.TMDBMapping, error) {
+	val, err := s.client.HGet(ctx, tmdbMappingKey, doubanID).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("redis hget error: %w", err)
+	}
+
+	var mapping model.TMDBMapping
+	if err := json.Unmarshal([]byte(val), &mapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mapping: %w", err)
+	}
+	return &mapping, nil
+}
+
+// Set stores a mapping, replacing any previous one for the subject
+func (s *MappingStore) Set(ctx context.Context, mapping *model.TMDBMapping) error {
+	if mapping.UpdatedAt == 0 {
+		mapping.UpdatedAt = time.Now().Unix()
+	}
+
+	data, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mapping: %w", err)
+	}
+
+	if err := s.client.HSet(ctx, tmdbMappingKey, mapping.DoubanID, data).Err(); err != nil {
+		return fmt.Errorf("redis hset error: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the mapping of a Douban subject. Reports whether one existed.
+func (s *MappingStore) Delete(ctx context.Context, doubanID string) (bool, error) {
+	n, err := s.client.HDel(ctx, tmdbMappingKey, doubanID).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis hdel error: %w", err)
+	}
+	return n > 0, nil
+}
+
+// List returns the mappings whose confidence is below maxConfidence, lowest
+// first. Manual overrides (confidence 1) are listed only when maxConfidence > 1.
+func (s *MappingStore) List(ctx context.Context, maxConfidence float64) ([]model.TMDBMapping, error) {
+	all, err := s.client.HGetAll(ctx, tmdbMappingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis hgetall error: %w", err)
+	}
+
+	mappings := []model.TMDBMapping{}
+	for _, val := range all {
+		var mapping model.TMDBMapping
+		if err := json.Unmarshal([]byte(val), &mapping); err != nil {
+			continue
+		}
+		if mapping.Confidence < maxConfidence {
+			mappings = append(mappings, mapping)
+		}
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].Confidence != mappings[j].Confidence {
+			return mappings[i].Confidence < mappings[j].Confidence
+		}
+		return mappings[i].DoubanID < mappings[j].DoubanID
+	})
+
+	return mappings, nil
+}
+
+// Close closes the Redis connection
+func (s *MappingStore) Close() error {
+	return s.client.Close()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
@@ -48,6 +49,29 @@ type OverallStats struct {
 	Uptime        int64        `json:"uptime_seconds"`
 }
 
+// DriftSample is a stored upstream payload that failed validation
+type DriftSample struct {
+	Time    int64  `json:"time"`
+	Reason  string `json:"reason"`
+	Payload string `json:"payload"`
+}
+
+// DriftStats represents schema drift statistics for an upstream endpoint
+type DriftStats struct {
+	Endpoint string        `json:"endpoint"`
+	Count    int64         `json:"count"`
+	LastSeen int64         `json:"last_seen"`
+	Samples  []DriftSample `json:"samples,omitempty"`
+}
+
+const (
+	driftCountsKey       = "metrics:drift:counts"
+	driftLastSeenKey     = "metrics:drift:last_seen"
+	driftSamplesPrefix   = "metrics:drift:samples:"
+	maxDriftSamples      = 10
+	maxDriftSampleLength = 4096
+)
+
 // NewMetrics creates a new Metrics instance
 func NewMetrics(redisURL string) (*Metrics, error) {
 	opt, err := redis.ParseURL(redisURL)
@@ -255,6 +279,75 @@ func (m *Metrics) getDailyTrend(ctx context.Context, days int) []DailyStats {
 	return trend
 }
 
+// RecordSchemaDrift records an upstream payload that failed validation.
+// Only the most recent samples per endpoint are kept, truncated to a few KB.
+func (m *Metrics) RecordSchemaDrift(ctx context.Context, endpoint, reason string, payload []byte) error {
+	if len(payload) > maxDriftSampleLength {
+		payload = payload[:maxDriftSampleLength]
+	}
+
+	sample, err := json.Marshal(DriftSample{
+		Time:    time.Now().Unix(),
+		Reason:  reason,
+		Payload: string(payload),
+	})
+	if err != nil {
+		return err
+	}
+
+	samplesKey := driftSamplesPrefix + endpoint
+
+	pipe := m.client.Pipeline()
+	pipe.HIncrBy(ctx, driftCountsKey, endpoint, 1)
+	pipe.HSet(ctx, driftLastSeenKey, endpoint, time.Now().Unix())
+	pipe.LPush(ctx, samplesKey, sample)
+	pipe.LTrim(ctx, samplesKey, 0, maxDriftSamples-1)
+	pipe.Expire(ctx, samplesKey, 7*24*time.Hour) // Keep 7 days
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to record schema drift")
+		return err
+	}
+	return nil
+}
+
+// GetSchemaDriftStats gets schema drift counters and samples for all endpoints
+func (m *Metrics) GetSchemaDriftStats(ctx context.Context) ([]DriftStats, error) {
+	counts, err := m.client.HGetAll(ctx, driftCountsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	lastSeen, _ := m.client.HGetAll(ctx, driftLastSeenKey).Result()
+
+	stats := make([]DriftStats, 0, len(counts))
+	for endpoint, countStr := range counts {
+		count, _ := strconv.ParseInt(countStr, 10, 64)
+		seen, _ := strconv.ParseInt(lastSeen[endpoint], 10, 64)
+
+		var samples []DriftSample
+		raw, _ := m.client.LRange(ctx, driftSamplesPrefix+endpoint, 0, -1).Result()
+		for _, r := range raw {
+			var sample DriftSample
+			if err := json.Unmarshal([]byte(r), &sample); err == nil {
+				samples = append(samples, sample)
+			}
+		}
+
+		stats = append(stats, DriftStats{
+			Endpoint: endpoint,
+			Count:    count,
+			LastSeen: seen,
+			Samples:  samples,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Count > stats[j].Count
+	})
+
+	return stats, nil
+}
+
 // RecordServerStart records server start time
 func (m *Metrics) RecordServerStart(ctx context.Context) {
 	m.client.Set(ctx, "metrics:server:start_time", time.Now().Unix(), 0)
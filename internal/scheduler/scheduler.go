@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Every runs fn once per interval until ctx is cancelled. The first run
+// happens after one interval; call sites that need a warm cache rely on the
+// request path to fill it.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Str("job", name).Dur("interval", interval).Msg("⏰ 定时任务已启动")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			if err := fn(ctx); err != nil {
+				log.Warn().Err(err).Str("job", name).Msg("定时任务执行失败")
+				continue
+			}
+			log.Info().Str("job", name).Dur("took", time.Since(start)).Msg("✅ 定时任务执行完成")
+		}
+	}
+}
@@ -1,25 +1,62 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 
 	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
 	"kerkerker-douban-service/pkg/httpclient"
 
 	"github.com/rs/zerolog/log"
 )
 
+// ErrInvalidPayload is returned when Douban answers with a payload that does
+// not match the expected schema. Such responses must not be cached.
+var ErrInvalidPayload = errors.New("invalid upstream payload")
+
 // DoubanService handles Douban API interactions
 type DoubanService struct {
-	client *httpclient.Client
+	client  *httpclient.Client
+	metrics *repository.Metrics
 }
 
 // NewDoubanService creates a new DoubanService
-func NewDoubanService(client *httpclient.Client) *DoubanService {
+func NewDoubanService(client *httpclient.Client, metrics *repository.Metrics) *DoubanService {
 	return &DoubanService{
-		client: client,
+		client:  client,
+		metrics: metrics,
+	}
+}
+
+// validatable is implemented by upstream payloads that can check their own shape
+type validatable interface {
+	Validate() error
+}
+
+// decodeAndValidate unmarshals an upstream payload and validates its shape,
+// recording schema drift when either step fails
+func (s *DoubanService) decodeAndValidate(endpoint string, data []byte, dest validatable) error {
+	err := json.Unmarshal(data, dest)
+	if err == nil {
+		err = dest.Validate()
+	}
+	if err == nil {
+		return nil
+	}
+
+	s.recordDrift(endpoint, err.Error(), data)
+	return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+}
+
+// recordDrift logs and stores an upstream payload that failed validation
+func (s *DoubanService) recordDrift(endpoint, reason string, data []byte) {
+	log.Warn().Str("endpoint", endpoint).Str("reason", reason).Msg("⚠️ 豆瓣响应结构异常")
+	if s.metrics != nil {
+		s.metrics.RecordSchemaDrift(context.Background(), endpoint, reason, data)
 	}
 }
 
@@ -39,7 +76,7 @@ func (s *DoubanService) SearchSubjects(subjectType, tag string, limit, start int
 	}
 
 	var result model.DoubanSearchResponse
-	if err := json.Unmarshal(data, &result); err != nil {
+	if err := s.decodeAndValidate("search_subjects", data, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse subjects response: %w", err)
 	}
 
@@ -61,13 +98,33 @@ func (s *DoubanService) GetSubjectAbstract(subjectID string) (*model.DoubanAbstr
 	}
 
 	var result model.DoubanAbstractResponse
-	if err := json.Unmarshal(data, &result); err != nil {
+	if err := s.decodeAndValidate("subject_abstract", data, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse subject abstract: %w", err)
 	}
 
 	return &result, nil
 }
 
+// tvOnlyTypes are Douban genres that only apply to TV
+var tvOnlyTypes = map[string]bool{"真人秀": true, "脱口秀": true}
+
+// IsTVAbstract reports whether an abstract describes a TV subject, from
+// is_tv, the episode count or a TV-only genre
+func IsTVAbstract(subject *model.DoubanAbstractSubject) bool {
+	if subject == nil {
+		return false
+	}
+	if subject.IsTV || subject.EpisodesCount != "" {
+		return true
+	}
+	for _, t := range subject.Types {
+		if tvOnlyTypes[t] {
+			return true
+		}
+	}
+	return false
+}
+
 // GetSubjectSuggest gets search suggestions
 func (s *DoubanService) GetSubjectSuggest(query string) ([]model.SuggestItem, error) {
 	u := fmt.Sprintf("https://movie.douban.com/j/subject_suggest?q=%s", url.QueryEscape(query))
@@ -110,43 +167,13 @@ func (s *DoubanService) GetPhotos(subjectID string, count int, photoType string)
 			ID:    p.ID,
 			Image: p.Image,
 			Thumb: p.Thumb,
+			Sizes: photoSizes(p.Image),
 		}
 	}
 
 	return photos, nil
 }
 
-// GetComments gets comments for a subject
-func (s *DoubanService) GetComments(subjectID string, limit int) ([]model.Comment, error) {
-	u := fmt.Sprintf("https://movie.douban.com/j/subject/%s/comments?start=0&limit=%d&sort=new_score&status=P",
-		subjectID, limit)
-
-	data, err := s.client.Fetch(u)
-	if err != nil {
-		log.Warn().Err(err).Str("subjectID", subjectID).Msg("Failed to fetch comments")
-		return []model.Comment{}, nil
-	}
-
-	var result model.DoubanCommentsResponse
-	if err := json.Unmarshal(data, &result); err != nil {
-		log.Warn().Err(err).Msg("Failed to parse comments")
-		return []model.Comment{}, nil
-	}
-
-	comments := make([]model.Comment, len(result.Comments))
-	for i, c := range result.Comments {
-		comments[i] = model.Comment{
-			ID:      c.ID,
-			Content: c.Content,
-			Author: model.CommentAuthor{
-				Name: c.Author.Name,
-			},
-		}
-	}
-
-	return comments, nil
-}
-
 // GetRecommendations gets recommendations for a subject
 func (s *DoubanService) GetRecommendations(subjectID string) ([]model.Subject, error) {
 	u := fmt.Sprintf("https://movie.douban.com/j/subject/%s/recommendations", subjectID)
@@ -198,10 +225,8 @@ func (s *DoubanService) AdvancedSearch(tags, sort, genres, yearRange string, sta
 		return nil, fmt.Errorf("failed to advanced search: %w", err)
 	}
 
-	var result struct {
-		Data []model.Subject `json:"data"`
-	}
-	if err := json.Unmarshal(data, &result); err != nil {
+	var result model.DoubanAdvancedSearchResponse
+	if err := s.decodeAndValidate("new_search_subjects", data, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse advanced search: %w", err)
 	}
 
@@ -236,3 +261,13 @@ func (s *DoubanService) HasProxy() bool {
 func (s *DoubanService) ProxyCount() int {
 	return s.client.ProxyCount()
 }
+
+// SessionStatus returns the health of the loaded cookie sessions
+func (s *DoubanService) SessionStatus() []httpclient.SessionStatus {
+	return s.client.SessionStatus()
+}
+
+// ActiveSessionCount returns the number of usable cookie sessions
+func (s *DoubanService) ActiveSessionCount() int {
+	return s.client.ActiveSessionCount()
+}
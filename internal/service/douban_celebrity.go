@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CelebrityWorksPageSize is the number of works per filmography page
+const CelebrityWorksPageSize = 10
+
+// Filmography sort orders accepted by GetCelebrityWorks
+const (
+	CelebrityWorksSortTime   = "time"
+	CelebrityWorksSortRating = "rating"
+)
+
+var (
+	// Douban is migrating celebrity pages to /personage/, links use either form
+	reCelebrityLink  = regexp.MustCompile(`<a[^>]+href="[^"]*/(?:celebrity|personage)/(\d+)/?"[^>]*>([^<]+)</a>`)
+	reCelebrityH1    = regexp.MustCompile(`(?s)<h1[^>]*>(.*?)</h1>`)
+	reCelebrityPhoto = regexp.MustCompile(`<img[^>]+src="(https?://img\d+\.doubanio\.com/view/(?:celebrity|personage)/[^"]+)"`)
+	reCelebrityInfo  = regexp.MustCompile(`(?s)<div class="info">\s*<ul[^>]*>(.*?)</ul>`)
+	reCelebrityProps = regexp.MustCompile(`(?s)<ul class="subject-property">(.*?)</ul>`)
+	reCelebrityIntro = regexp.MustCompile(`(?s)<div id="intro"[^>]*>.*?<div class="bd">(.*?)</div>`)
+	reCelebrityDesc  = regexp.MustCompile(`(?s)<section class="subject-intro">.*?<div class="content">(.*?)</div>`)
+	reIMDbName       = regexp.MustCompile(`nm\d+`)
+	reLatinName      = regexp.MustCompile(`^(.*?[^\x00-\x7F].*?)\s+([\x00-\x7F]+)$`)
+
+	reWorkTitle = regexp.MustCompile(`(?s)<h6>\s*<a[^>]+>([^<]+)</a>`)
+	reWorkYear  = regexp.MustCompile(`<span>\((\d{4})\)</span>`)
+	reWorkRoles = regexp.MustCompile(`<span>\[\s*([^\]]*?)\s*\]</span>`)
+	reWorkCover = regexp.MustCompile(`<img[^>]+src="([^"]+)"`)
+	reWorkRate  = regexp.MustCompile(`<span class="rating_nums">([\d.]+)</span>`)
+	reWorkTotal = regexp.MustCompile(`共\s*(\d+)\s*条`)
+)
+
+// GetCelebrity fetches a celebrity profile
+func (s *DoubanService) GetCelebrity(celebrityID string) (*model.Celebrity, error) {
+	if !model.IsDoubanID(celebrityID) {
+		return nil, fmt.Errorf("invalid celebrity id: %q", celebrityID)
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/celebrity/%s/", celebrityID)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch celebrity: %w", err)
+	}
+
+	celebrity := parseCelebrity(celebrityID, string(data))
+	if celebrity == nil {
+		s.recordDrift("celebrity", "no name parsed", data)
+		return nil, fmt.Errorf("failed to parse celebrity: %w", ErrInvalidPayload)
+	}
+	celebrity.URL = u
+
+	return celebrity, nil
+}
+
+// GetCelebrityWorks fetches one page of a celebrity's filmography.
+// sortBy is CelebrityWorksSortTime or CelebrityWorksSortRating.
+func (s *DoubanService) GetCelebrityWorks(celebrityID string, page int, sortBy string) (*model.CelebrityWorks, error) {
+	if !model.IsDoubanID(celebrityID) {
+		return nil, fmt.Errorf("invalid celebrity id: %q", celebrityID)
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	// 豆瓣按评分排序的参数为 vote
+	doubanSort := "time"
+	if sortBy == CelebrityWorksSortRating {
+		doubanSort = "vote"
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/celebrity/%s/movies?start=%d&format=pic&sortby=%s",
+		celebrityID, (page-1)*CelebrityWorksPageSize, doubanSort)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch celebrity works: %w", err)
+	}
+
+	works := parseCelebrityWorks(string(data))
+	// 超出最后一页时为空列表，仅首页为空才视为页面结构变化
+	if page == 1 && len(works.Works) == 0 {
+		s.recordDrift("celebrity_works", "no works parsed", data)
+		return nil, fmt.Errorf("failed to parse celebrity works: %w", ErrInvalidPayload)
+	}
+
+	log.Debug().Str("id", celebrityID).Int("page", page).Int("count", len(works.Works)).Msg("Fetched celebrity works")
+
+	return works, nil
+}
+
+// parseCelebrity extracts the profile from a celebrity (or personage) page.
+// Returns nil when no name is found.
+func parseCelebrity(celebrityID, page string) *model.Celebrity {
+	heading := cleanText(firstMatch(reCelebrityH1, page))
+	if heading == "" {
+		return nil
+	}
+
+	celebrity := &model.Celebrity{
+		ID:    celebrityID,
+		Name:  heading,
+		Photo: firstMatch(reCelebrityPhoto, page),
+	}
+
+	// h1 为 "中文名 English Name"，拆分出外文名
+	if m := reLatinName.FindStringSubmatch(heading); m != nil {
+		celebrity.Name = strings.TrimSpace(m[1])
+		celebrity.NameEn = strings.TrimSpace(m[2])
+	}
+
+	info := parseCelebrityInfo(page)
+	celebrity.Gender = info["性别"]
+	celebrity.Constellation = info["星座"]
+	celebrity.BirthDate = firstNonEmpty(info["出生日期"], info["生卒日期"])
+	celebrity.BirthPlace = info["出生地"]
+	celebrity.DeathDate = info["去世日期"]
+	celebrity.Roles = splitList(info["职业"], "/")
+	celebrity.IMDbID = reIMDbName.FindString(firstNonEmpty(info["imdb编号"], info["IMDb编号"]))
+
+	// 生卒日期: 1929-05-04 至 1993-01-20
+	if from, to, ok := strings.Cut(celebrity.BirthDate, "至"); ok {
+		celebrity.BirthDate = strings.TrimSpace(from)
+		celebrity.DeathDate = strings.TrimSpace(to)
+	}
+
+	for _, key := range []string{"更多外文名", "更多中文名"} {
+		celebrity.OtherNames = append(celebrity.OtherNames, splitList(info[key], "/")...)
+	}
+
+	summary := firstMatch(reCelebrityIntro, page)
+	if summary == "" {
+		summary = firstMatch(reCelebrityDesc, page)
+	}
+	if full := firstMatch(reSummaryAll, summary); full != "" {
+		summary = full
+	}
+	celebrity.Summary = cleanMultiline(summary)
+
+	return celebrity
+}
+
+// parseCelebrityInfo reads the "label: value" list of both the legacy
+// celebrity page and the newer personage page
+func parseCelebrityInfo(page string) map[string]string {
+	block := firstMatch(reCelebrityInfo, page)
+	if block == "" {
+		block = firstMatch(reCelebrityProps, page)
+	}
+
+	return parseLabeledItems(block)
+}
+
+// parseCelebrityWorks extracts works from a filmography page (format=pic)
+func parseCelebrityWorks(page string) *model.CelebrityWorks {
+	works := &model.CelebrityWorks{
+		Works: []model.CelebrityWork{},
+		Total: parseCount(firstMatch(reWorkTotal, page)),
+	}
+
+	for _, block := range splitBlocks(page, "<li>") {
+		id := firstMatch(reSubjectID, block)
+		title := cleanText(firstMatch(reWorkTitle, block))
+		if id == "" || title == "" {
+			continue
+		}
+
+		works.Works = append(works.Works, model.CelebrityWork{
+			ID:    id,
+			Title: title,
+			Cover: firstMatch(reWorkCover, block),
+			URL:   fmt.Sprintf("https://movie.douban.com/subject/%s/", id),
+			Year:  firstMatch(reWorkYear, block),
+			Rate:  firstMatch(reWorkRate, block),
+			Roles: strings.Fields(cleanText(firstMatch(reWorkRoles, block))),
+		})
+	}
+
+	return works
+}
+
+// parseCelebrityRefs pairs the names of a credits value with the celebrity
+// IDs of their links, e.g. `<a href="/celebrity/1054521/">蒂姆·罗宾斯</a> / 某某`
+func parseCelebrityRefs(value string) []model.CelebrityRef {
+	ids := make(map[string]string)
+	for _, m := range reCelebrityLink.FindAllStringSubmatch(value, -1) {
+		ids[cleanText(m[2])] = m[1]
+	}
+
+	var refs []model.CelebrityRef
+	for _, name := range splitList(cleanText(value), "/") {
+		refs = append(refs, model.CelebrityRef{ID: ids[name], Name: name})
+	}
+	return refs
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultChartInterval is the top 10% of a genre by score percentage
+const DefaultChartInterval = "100:90"
+
+// ChartGenre is a genre of the Douban chart (排行榜) pages
+type ChartGenre struct {
+	ID   int    `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// ChartGenres lists the genres of https://movie.douban.com/chart
+var ChartGenres = []ChartGenre{
+	{11, "drama", "剧情"},
+	{24, "comedy", "喜剧"},
+	{5, "action", "动作"},
+	{13, "romance", "爱情"},
+	{17, "scifi", "科幻"},
+	{25, "animation", "动画"},
+	{10, "mystery", "悬疑"},
+	{19, "thriller", "惊悚"},
+	{20, "horror", "恐怖"},
+	{1, "documentary", "纪录片"},
+	{23, "short", "短片"},
+	{14, "music", "音乐"},
+	{7, "musical", "歌舞"},
+	{28, "family", "家庭"},
+	{8, "children", "儿童"},
+	{2, "biography", "传记"},
+	{4, "history", "历史"},
+	{22, "war", "战争"},
+	{3, "crime", "犯罪"},
+	{27, "western", "西部"},
+	{16, "fantasy", "奇幻"},
+	{15, "adventure", "冒险"},
+	{12, "disaster", "灾难"},
+	{29, "wuxia", "武侠"},
+	{30, "costume", "古装"},
+	{18, "sports", "运动"},
+	{31, "noir", "黑色电影"},
+}
+
+// reChartInterval matches score percentage ranges like "100:90" or "30:20"
+var reChartInterval = regexp.MustCompile(`^(100|[1-9]0):(90|[1-8]0|0)$`)
+
+// LookupChartGenre resolves a genre slug, Chinese name or numeric ID
+func LookupChartGenre(s string) (ChartGenre, bool) {
+	id, _ := strconv.Atoi(s)
+	for _, g := range ChartGenres {
+		if g.Slug == s || g.Name == s || g.ID == id {
+			return g, true
+		}
+	}
+	return ChartGenre{}, false
+}
+
+// IsChartInterval reports whether interval is a valid "high:low" score
+// percentage range, e.g. "100:90" for the top 10%
+func IsChartInterval(interval string) bool {
+	m := reChartInterval.FindStringSubmatch(interval)
+	if m == nil {
+		return false
+	}
+	high, _ := strconv.Atoi(m[1])
+	low, _ := strconv.Atoi(m[2])
+	return high > low
+}
+
+// GetChart fetches a page of a genre chart ranked by score percentage.
+// Total is left 0; it is fetched separately with GetChartTotal.
+func (s *DoubanService) GetChart(genreID int, interval string, start, limit int) (*model.ChartPage, error) {
+	u := fmt.Sprintf("https://movie.douban.com/j/chart/top_list?type=%d&interval_id=%s&action=&start=%d&limit=%d",
+		genreID, interval, start, limit)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chart: %w", err)
+	}
+
+	var result model.DoubanChartResponse
+	if err := s.decodeAndValidate("chart_top_list", data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse chart response: %w", err)
+	}
+
+	page := &model.ChartPage{
+		Items: make([]model.ChartItem, len(result)),
+	}
+	for i, item := range result {
+		page.Items[i] = model.ChartItem{
+			Rank:        item.Rank,
+			ID:          item.ID,
+			Title:       item.Title,
+			Cover:       item.CoverURL,
+			URL:         item.URL,
+			Rate:        item.Score,
+			Votes:       item.VoteCount,
+			Types:       item.Types,
+			Regions:     item.Regions,
+			ReleaseDate: item.ReleaseDate,
+			Actors:      item.Actors,
+		}
+	}
+
+	log.Debug().Int("genre", genreID).Str("interval", interval).Int("count", len(page.Items)).Msg("Fetched chart")
+
+	return page, nil
+}
+
+// GetChartTotal returns the number of subjects in a chart interval, or 0
+// when the count endpoint fails
+func (s *DoubanService) GetChartTotal(genreID int, interval string) int {
+	u := fmt.Sprintf("https://movie.douban.com/j/chart/top_list_count?type=%d&interval_id=%s", genreID, interval)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		log.Warn().Err(err).Int("genre", genreID).Msg("Failed to fetch chart count")
+		return 0
+	}
+
+	var result model.DoubanChartCountResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse chart count")
+		return 0
+	}
+	return result.Total
+}
+
+// ChartSubjects converts chart items to list subjects
+func ChartSubjects(items []model.ChartItem) []model.Subject {
+	subjects := make([]model.Subject, len(items))
+	for i, item := range items {
+		subjects[i] = model.Subject{
+			ID:    item.ID,
+			Title: item.Title,
+			Rate:  item.Rate,
+			Cover: item.Cover,
+			URL:   item.URL,
+		}
+	}
+	return subjects
+}
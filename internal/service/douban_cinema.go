@@ -0,0 +1,179 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	reCityName       = regexp.MustCompile(`^[a-z]+$`)
+	reNowPlaying     = regexp.MustCompile(`(?s)<div id="nowplaying">(.*?)(?:<div id="upcoming">|$)`)
+	reDataAttr       = regexp.MustCompile(`data-([a-z]+)="([^"]*)"`)
+	reShowingSoon    = regexp.MustCompile(`(?s)<div id="showing-soon"[^>]*>(.*)`)
+	reCinemaTitle    = regexp.MustCompile(`(?s)<h3>\s*<a[^>]*>(.*?)</a>`)
+	reCinemaDetail   = regexp.MustCompile(`(?s)<li class="dt[^"]*">(.*?)</li>`)
+	reCinemaWish     = regexp.MustCompile(`(\d+)\s*人想看`)
+	reChineseDate    = regexp.MustCompile(`(?:(\d{4})年)?(\d{1,2})月(\d{1,2})日`)
+	cinemaWhitespace = strings.NewReplacer("\n", " ", "\t", " ")
+)
+
+// IsCityName reports whether city looks like a Douban city slug (e.g. beijing)
+func IsCityName(city string) bool {
+	return reCityName.MatchString(city)
+}
+
+// GetNowPlaying fetches the movies now playing in a city
+func (s *DoubanService) GetNowPlaying(city string) ([]model.CinemaMovie, error) {
+	if !IsCityName(city) {
+		return nil, fmt.Errorf("invalid city: %q", city)
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/cinema/nowplaying/%s/", city)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch now playing: %w", err)
+	}
+
+	movies := parseNowPlaying(string(data))
+	if len(movies) == 0 {
+		s.recordDrift("cinema_nowplaying", "no movies parsed", data)
+		return nil, fmt.Errorf("failed to parse now playing: %w", ErrInvalidPayload)
+	}
+
+	log.Debug().Str("city", city).Int("count", len(movies)).Msg("Fetched now playing")
+
+	return movies, nil
+}
+
+// GetComingSoon fetches the movies coming soon in a city, with release
+// dates resolved to full dates
+func (s *DoubanService) GetComingSoon(city string) ([]model.CinemaMovie, error) {
+	if !IsCityName(city) {
+		return nil, fmt.Errorf("invalid city: %q", city)
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/cinema/later/%s/", city)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch coming soon: %w", err)
+	}
+
+	html := string(data)
+	movies := parseComingSoon(html, time.Now())
+	if len(movies) == 0 && !strings.Contains(html, `id="showing-soon"`) {
+		s.recordDrift("cinema_later", "no coming soon list found", data)
+		return nil, fmt.Errorf("failed to parse coming soon: %w", ErrInvalidPayload)
+	}
+
+	log.Debug().Str("city", city).Int("count", len(movies)).Msg("Fetched coming soon")
+
+	return movies, nil
+}
+
+// parseNowPlaying reads the data-* attributes of the now playing list items
+func parseNowPlaying(page string) []model.CinemaMovie {
+	var movies []model.CinemaMovie
+
+	section := firstMatch(reNowPlaying, page)
+	for _, block := range splitBlocks(section, `class="list-item"`) {
+		// 属性位于 class 之后；海报等子元素在 > 之后
+		head, _, _ := strings.Cut(block, ">")
+		attrs := make(map[string]string)
+		for _, m := range reDataAttr.FindAllStringSubmatch(head, -1) {
+			attrs[m[1]] = cleanText(m[2])
+		}
+		id := attrs["subject"]
+		if !model.IsDoubanID(id) || attrs["title"] == "" {
+			continue
+		}
+
+		movie := model.CinemaMovie{
+			ID:        id,
+			Title:     attrs["title"],
+			Cover:     firstMatch(reTop250Cover, block),
+			URL:       fmt.Sprintf("https://movie.douban.com/subject/%s/", id),
+			Votes:     parseCount(attrs["votecount"]),
+			Year:      attrs["release"],
+			Duration:  attrs["duration"],
+			Regions:   splitList(attrs["region"], " "),
+			Directors: splitList(attrs["director"], "/"),
+			Actors:    splitList(attrs["actors"], "/"),
+		}
+		// 评价人数不足时豆瓣给出 0 分
+		if attrs["score"] != "" && attrs["score"] != "0" {
+			movie.Rate = attrs["score"]
+		}
+
+		movies = append(movies, movie)
+	}
+
+	return movies
+}
+
+// parseComingSoon extracts the coming soon items. Each item lists its
+// release date ("10月20日"), genres, regions and want-to-see count.
+func parseComingSoon(page string, now time.Time) []model.CinemaMovie {
+	movies := []model.CinemaMovie{}
+
+	section := firstMatch(reShowingSoon, page)
+	for _, block := range splitBlocks(section, `<div class="item mod`) {
+		id := firstMatch(reSubjectID, block)
+		title := cleanText(firstMatch(reCinemaTitle, block))
+		if id == "" || title == "" {
+			continue
+		}
+
+		movie := model.CinemaMovie{
+			ID:    id,
+			Title: title,
+			Cover: firstMatch(reTop250Cover, block),
+			URL:   fmt.Sprintf("https://movie.douban.com/subject/%s/", id),
+			Wish:  parseCount(firstMatch(reCinemaWish, block)),
+		}
+
+		var details []string
+		for _, m := range reCinemaDetail.FindAllStringSubmatch(block, -1) {
+			details = append(details, cleanText(cinemaWhitespace.Replace(m[1])))
+		}
+		if len(details) > 0 {
+			movie.ReleaseDate = resolveChineseDate(details[0], now)
+		}
+		if len(details) > 1 {
+			movie.Genres = splitList(details[1], "/")
+		}
+		if len(details) > 2 {
+			movie.Regions = splitList(details[2], "/")
+		}
+
+		movies = append(movies, movie)
+	}
+
+	return movies
+}
+
+// resolveChineseDate turns "10月20日" into a full date. Dates without a year
+// that fall more than a month before now belong to next year.
+func resolveChineseDate(s string, now time.Time) string {
+	m := reChineseDate.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+
+	year := now.Year()
+	if m[1] != "" {
+		year = parseCount(m[1])
+	}
+	date := time.Date(year, time.Month(parseCount(m[2])), parseCount(m[3]), 0, 0, 0, 0, now.Location())
+	if m[1] == "" && date.Before(now.AddDate(0, -1, 0)) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date.Format("2006-01-02")
+}
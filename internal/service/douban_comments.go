@@ -0,0 +1,277 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// CommentsMaxLimit is the largest page Douban serves for short comments
+	CommentsMaxLimit = 20
+	// ReviewsPageSize is the fixed page size of Douban review lists
+	ReviewsPageSize = 20
+)
+
+// Sort orders and watched-status filters for comments and reviews
+const (
+	CommentSortHot       = "hot"
+	CommentSortNew       = "new"
+	CommentStatusWatched = "watched"
+	CommentStatusWish    = "wish"
+)
+
+var (
+	reCommentID       = regexp.MustCompile(`data-cid="(\d+)"`)
+	reCommentAvatar   = regexp.MustCompile(`(?s)<div class="avatar">.*?<img src="([^"]+)"`)
+	reCommentAuthor   = regexp.MustCompile(`(?s)<span class="comment-info">\s*<a href="[^"]*/people/([^/"]+)/?"[^>]*>([^<]+)</a>`)
+	reCommentStatus   = regexp.MustCompile(`(?s)<span class="comment-info">\s*<a[^>]*>[^<]*</a>\s*<span>([^<]+)</span>`)
+	reCommentRating   = regexp.MustCompile(`allstar(\d)0 rating`)
+	reCommentTime     = regexp.MustCompile(`<span class="comment-time[^"]*" title="([^"]+)"`)
+	reCommentLocation = regexp.MustCompile(`<span class="comment-location">([^<]*)</span>`)
+	reCommentVotes    = regexp.MustCompile(`<span class="votes[^"]*">(\d+)</span>`)
+	reCommentContent  = regexp.MustCompile(`(?s)<span class="short">(.*?)</span>`)
+
+	reReviewID       = regexp.MustCompile(`/review/(\d+)/`)
+	reReviewAuthor   = regexp.MustCompile(`(?s)<a href="[^"]*/people/([^/"]+)/?" class="avator">\s*<img[^>]+src="([^"]+)"`)
+	reReviewName     = regexp.MustCompile(`<a href="[^"]*" class="name">([^<]+)</a>`)
+	reReviewRating   = regexp.MustCompile(`allstar(\d)0 main-title-rating`)
+	reReviewTime     = regexp.MustCompile(`class="main-meta">([^<]+)</span>`)
+	reReviewTitle    = regexp.MustCompile(`(?s)<h2>\s*<a[^>]*>(.*?)</a>`)
+	reReviewSummary  = regexp.MustCompile(`(?s)<div class="short-content">(.*?)</div>`)
+	reReviewSpoiler  = regexp.MustCompile(`(?s)<p class="spoiler-tip">.*?</p>`)
+	reReviewUseful   = regexp.MustCompile(`id="r-useful_count-\d+">\s*(\d*)`)
+	reReviewUseless  = regexp.MustCompile(`id="r-useless_count-\d+">\s*(\d*)`)
+	reReviewReplies  = regexp.MustCompile(`(\d+)回应`)
+	reReviewTotal    = regexp.MustCompile(`的影评\s*\((\d+)\)`)
+	reReviewUnfolder = regexp.MustCompile(`[\s.…]*\(\s*展开\s*\)\s*$`)
+)
+
+// commentStatus is Douban's status code for a filter and the tab counter
+// showing its total, e.g. "看过(123456)"
+type commentStatus struct {
+	code  string
+	total *regexp.Regexp
+}
+
+var commentStatuses = map[string]commentStatus{
+	CommentStatusWatched: {"P", regexp.MustCompile(`看过\((\d+)\)`)},
+	CommentStatusWish:    {"F", regexp.MustCompile(`想看\((\d+)\)`)},
+}
+
+// GetComments gets the first hot comments of a subject from the JSON API.
+// The detail endpoint uses it; paginated lists go through GetCommentsPage.
+func (s *DoubanService) GetComments(subjectID string, limit int) ([]model.Comment, error) {
+	u := fmt.Sprintf("https://movie.douban.com/j/subject/%s/comments?start=0&limit=%d&sort=new_score&status=P",
+		subjectID, limit)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		log.Warn().Err(err).Str("subjectID", subjectID).Msg("Failed to fetch comments")
+		return []model.Comment{}, nil
+	}
+
+	var result model.DoubanCommentsResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse comments")
+		return []model.Comment{}, nil
+	}
+
+	comments := make([]model.Comment, len(result.Comments))
+	for i, c := range result.Comments {
+		comments[i] = model.Comment{
+			ID:      c.ID,
+			Content: c.Content,
+			Author: model.CommentAuthor{
+				Name: c.Author.Name,
+			},
+		}
+	}
+
+	return comments, nil
+}
+
+// GetCommentsPage fetches short comments starting at offset start.
+// sort is CommentSortHot or CommentSortNew, status is CommentStatusWatched
+// or CommentStatusWish.
+func (s *DoubanService) GetCommentsPage(subjectID string, start, limit int, sort, status string) (*model.CommentsPage, error) {
+	if !model.IsDoubanID(subjectID) {
+		return nil, fmt.Errorf("invalid subject id: %q", subjectID)
+	}
+	if limit <= 0 || limit > CommentsMaxLimit {
+		limit = CommentsMaxLimit
+	}
+
+	doubanSort := "new_score"
+	if sort == CommentSortNew {
+		doubanSort = "time"
+	}
+	filter, ok := commentStatuses[status]
+	if !ok {
+		filter = commentStatuses[CommentStatusWatched]
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/subject/%s/comments?start=%d&limit=%d&sort=%s&status=%s",
+		subjectID, start, limit, doubanSort, filter.code)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+
+	html := string(data)
+	comments := parseComments(html)
+	if start == 0 && len(comments) == 0 && !strings.Contains(html, `id="comments"`) {
+		s.recordDrift("comments", "no comment list found", data)
+		return nil, fmt.Errorf("failed to parse comments: %w", ErrInvalidPayload)
+	}
+
+	total := parseCount(firstMatch(filter.total, html))
+	hasMore := start+len(comments) < total
+	if total == 0 {
+		hasMore = len(comments) == limit
+	}
+
+	return &model.CommentsPage{
+		Comments: comments,
+		Total:    total,
+		HasMore:  hasMore,
+	}, nil
+}
+
+// GetReviews fetches one page of long-form reviews starting at offset start.
+// Full texts are fetched separately with GetReviewFullText.
+func (s *DoubanService) GetReviews(subjectID string, start int, sort string) (*model.ReviewsPage, error) {
+	if !model.IsDoubanID(subjectID) {
+		return nil, fmt.Errorf("invalid subject id: %q", subjectID)
+	}
+
+	doubanSort := "hotest"
+	if sort == CommentSortNew {
+		doubanSort = "time"
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/subject/%s/reviews?start=%d&sort=%s", subjectID, start, doubanSort)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+
+	html := string(data)
+	reviews := parseReviews(html)
+	if start == 0 && len(reviews) == 0 && !strings.Contains(html, "review-list") {
+		s.recordDrift("reviews", "no review list found", data)
+		return nil, fmt.Errorf("failed to parse reviews: %w", ErrInvalidPayload)
+	}
+
+	total := parseCount(firstMatch(reReviewTotal, html))
+	hasMore := start+len(reviews) < total
+	if total == 0 {
+		hasMore = len(reviews) == ReviewsPageSize
+	}
+
+	return &model.ReviewsPage{
+		Reviews: reviews,
+		Total:   total,
+		HasMore: hasMore,
+	}, nil
+}
+
+// GetReviewFullText fetches the full text of a review as plain text
+func (s *DoubanService) GetReviewFullText(reviewID string) (string, error) {
+	if !model.IsDoubanID(reviewID) {
+		return "", fmt.Errorf("invalid review id: %q", reviewID)
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/j/review/%s/full", reviewID)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch review full text: %w", err)
+	}
+
+	var result model.DoubanReviewFull
+	if err := json.Unmarshal(data, &result); err != nil {
+		s.recordDrift("review_full", "invalid json: "+err.Error(), data)
+		return "", fmt.Errorf("failed to parse review full text: %w", ErrInvalidPayload)
+	}
+
+	body := result.HTML
+	if body == "" {
+		body = result.Body
+	}
+	return cleanMultiline(body), nil
+}
+
+// parseComments extracts the short comments of a comments page
+func parseComments(page string) []model.Comment {
+	comments := []model.Comment{}
+
+	for _, block := range splitBlocks(page, `<div class="comment-item`) {
+		content := cleanMultiline(firstMatch(reCommentContent, block))
+		author := reCommentAuthor.FindStringSubmatch(block)
+		if content == "" || author == nil {
+			continue
+		}
+
+		comments = append(comments, model.Comment{
+			ID:      firstMatch(reCommentID, block),
+			Content: content,
+			Author: model.CommentAuthor{
+				ID:     author[1],
+				Name:   cleanText(author[2]),
+				Avatar: firstMatch(reCommentAvatar, block),
+			},
+			Rating:    parseCount(firstMatch(reCommentRating, block)),
+			Status:    cleanText(firstMatch(reCommentStatus, block)),
+			CreatedAt: strings.TrimSpace(firstMatch(reCommentTime, block)),
+			Location:  cleanText(firstMatch(reCommentLocation, block)),
+			Votes:     parseCount(firstMatch(reCommentVotes, block)),
+		})
+	}
+
+	return comments
+}
+
+// parseReviews extracts the reviews of a review list page (without full text)
+func parseReviews(page string) []model.Review {
+	reviews := []model.Review{}
+
+	for _, block := range splitBlocks(page, `<div class="main review-item"`) {
+		id := firstMatch(reReviewID, block)
+		title := cleanText(firstMatch(reReviewTitle, block))
+		if id == "" || title == "" {
+			continue
+		}
+
+		summary := reReviewSpoiler.ReplaceAllString(firstMatch(reReviewSummary, block), "")
+		review := model.Review{
+			ID:           id,
+			Title:        title,
+			Summary:      reReviewUnfolder.ReplaceAllString(cleanMultiline(summary), ""),
+			Rating:       parseCount(firstMatch(reReviewRating, block)),
+			CreatedAt:    cleanText(firstMatch(reReviewTime, block)),
+			UsefulCount:  parseCount(firstMatch(reReviewUseful, block)),
+			UselessCount: parseCount(firstMatch(reReviewUseless, block)),
+			ReplyCount:   parseCount(firstMatch(reReviewReplies, block)),
+			URL:          fmt.Sprintf("https://movie.douban.com/review/%s/", id),
+			Author: model.CommentAuthor{
+				Name: cleanText(firstMatch(reReviewName, block)),
+			},
+		}
+		if m := reReviewAuthor.FindStringSubmatch(block); m != nil {
+			review.Author.ID = m[1]
+			review.Author.Avatar = m[2]
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DoulistPageSize is the fixed page size of Douban doulist pages
+	DoulistPageSize = 25
+	// MaxDoulistPages caps the pages fetched for one doulist (500 items)
+	MaxDoulistPages = 20
+	// DoulistFetchDelay paces doulist page requests, also between doulists
+	// in the refresh job
+	DoulistFetchDelay = 2 * time.Second
+)
+
+var (
+	reDoulistTitle     = regexp.MustCompile(`(?s)<div id="content">\s*<h1>(.*?)</h1>`)
+	reDoulistAbout     = regexp.MustCompile(`(?s)<div class="doulist-about">(.*?)</div>`)
+	reDoulistAuthor    = regexp.MustCompile(`(?s)<div class="meta">\s*<a[^>]*>([^<]+)</a>`)
+	reDoulistUpdated   = regexp.MustCompile(`(\d{4}-\d{2}-\d{2})\s*更新`)
+	reDoulistPages     = regexp.MustCompile(`data-total-page="(\d+)"`)
+	reDoulistPos       = regexp.MustCompile(`<span class="pos">\s*(\d+)\s*</span>`)
+	reDoulistItemTitle = regexp.MustCompile(`(?s)<div class="title">\s*<a href="([^"]+)"[^>]*>(.*?)</a>`)
+	reDoulistItemPost  = regexp.MustCompile(`(?s)<div class="post">.*?<img[^>]+src="([^"]+)"`)
+	reDoulistItemRate  = regexp.MustCompile(`<span class="rating_nums">([\d.]+)</span>`)
+	reDoulistAbstract  = regexp.MustCompile(`(?s)<div class="abstract">(.*?)</div>`)
+	reDoulistComment   = regexp.MustCompile(`(?s)<blockquote class="comment">(.*?)</blockquote>`)
+	reDoulistCommentLb = regexp.MustCompile(`^评语[:：]\s*`)
+)
+
+// GetDoulist fetches a doulist with its items in list order, following
+// pagination up to maxPages pages (at most MaxDoulistPages) one page every
+// DoulistFetchDelay. Truncated is set when pages were left unfetched.
+func (s *DoubanService) GetDoulist(doulistID string, maxPages int) (*model.Doulist, error) {
+	if !model.IsDoubanID(doulistID) {
+		return nil, fmt.Errorf("invalid doulist id: %q", doulistID)
+	}
+
+	baseURL := fmt.Sprintf("https://www.douban.com/doulist/%s/", doulistID)
+
+	maxPages = min(max(maxPages, 1), MaxDoulistPages)
+
+	var doulist *model.Doulist
+	pages, fetched := 1, 0
+	for page := 0; page < pages && page < maxPages; page++ {
+		if page > 0 {
+			// 逐页抓取，避免短时间内集中请求
+			time.Sleep(DoulistFetchDelay)
+		}
+		u := fmt.Sprintf("%s?start=%d&sort=seq", baseURL, page*DoulistPageSize)
+
+		data, err := s.client.Fetch(u)
+		if err != nil {
+			// 已获取的页面仍然可用，只有首页失败才返回错误
+			if doulist != nil {
+				log.Warn().Err(err).Str("id", doulistID).Int("page", page+1).Msg("Failed to fetch doulist page")
+				break
+			}
+			return nil, fmt.Errorf("failed to fetch doulist: %w", err)
+		}
+
+		html := string(data)
+		items := parseDoulistItems(html, page*DoulistPageSize)
+
+		if doulist == nil {
+			doulist = parseDoulistHeader(doulistID, html)
+			if doulist == nil {
+				s.recordDrift("doulist", "no title parsed", data)
+				return nil, fmt.Errorf("failed to parse doulist: %w", ErrInvalidPayload)
+			}
+			doulist.URL = baseURL
+			if n := parseCount(firstMatch(reDoulistPages, html)); n > 0 {
+				pages = n
+			}
+		}
+
+		doulist.Items = append(doulist.Items, items...)
+		fetched++
+
+		// 页码信息缺失时按是否满页判断
+		if pages == 1 && len(items) == DoulistPageSize {
+			pages = page + 2
+		}
+	}
+
+	doulist.Total = len(doulist.Items)
+	doulist.Truncated = fetched < pages
+
+	log.Debug().Str("id", doulistID).Int("items", doulist.Total).Msg("Fetched doulist")
+
+	return doulist, nil
+}
+
+// DoulistSubjects converts doulist items to list subjects, skipping items
+// that are not Douban subjects (books, deleted entries, ...)
+func DoulistSubjects(items []model.DoulistItem) []model.Subject {
+	subjects := make([]model.Subject, 0, len(items))
+	for _, item := range items {
+		if item.ID == "" {
+			continue
+		}
+		subjects = append(subjects, model.Subject{
+			ID:    item.ID,
+			Title: item.Title,
+			Rate:  item.Rate,
+			Cover: item.Cover,
+			URL:   item.URL,
+		})
+	}
+	return subjects
+}
+
+// parseDoulistHeader extracts the doulist's own fields. Returns nil when
+// no title is found.
+func parseDoulistHeader(doulistID, page string) *model.Doulist {
+	title := cleanText(firstMatch(reDoulistTitle, page))
+	if title == "" {
+		return nil
+	}
+
+	return &model.Doulist{
+		ID:          doulistID,
+		Title:       title,
+		Description: cleanMultiline(firstMatch(reDoulistAbout, page)),
+		Author:      cleanText(firstMatch(reDoulistAuthor, page)),
+		UpdatedAt:   firstMatch(reDoulistUpdated, page),
+		Items:       []model.DoulistItem{},
+	}
+}
+
+// parseDoulistItems extracts the items of a doulist page. offset is the
+// position of the page's first item, used when the page shows no numbers.
+func parseDoulistItems(page string, offset int) []model.DoulistItem {
+	var items []model.DoulistItem
+
+	for _, block := range splitBlocks(page, `<div class="doulist-item"`) {
+		m := reDoulistItemTitle.FindStringSubmatch(block)
+		if m == nil {
+			continue
+		}
+		title := cleanText(m[2])
+		if title == "" {
+			continue
+		}
+
+		position := parseCount(firstMatch(reDoulistPos, block))
+		if position == 0 {
+			position = offset + len(items) + 1
+		}
+
+		comment := cleanMultiline(firstMatch(reDoulistComment, block))
+
+		items = append(items, model.DoulistItem{
+			Position: position,
+			ID:       firstMatch(reSubjectID, m[1]),
+			Title:    title,
+			Cover:    firstMatch(reDoulistItemPost, block),
+			URL:      m[1],
+			Rate:     firstMatch(reDoulistItemRate, block),
+			Abstract: cleanMultiline(firstMatch(reDoulistAbstract, block)),
+			Comment:  reDoulistCommentLb.ReplaceAllString(comment, ""),
+		})
+	}
+
+	return items
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxEpisodePages caps per-episode page requests; episodes beyond it
+	// are listed by number only
+	maxEpisodePages = 20
+	// episodePageDelay paces episode page requests, which are fetched one
+	// at a time to avoid bursts against Douban
+	episodePageDelay = 500 * time.Millisecond
+)
+
+var (
+	reEpisodeLink   = regexp.MustCompile(`/subject/\d+/episode/(\d+)/`)
+	reSeasonSelect  = regexp.MustCompile(`(?s)<select id="season"[^>]*>(.*?)</select>`)
+	reSeasonOption  = regexp.MustCompile(`<option value="(\d+)"([^>]*)>\s*(\d+)\s*</option>`)
+	reEpisodeInfo   = regexp.MustCompile(`(?s)<ul class="ep-info">(.*?)</ul>`)
+	reEpisodeAbsent = regexp.MustCompile(`^暂无`)
+)
+
+// GetEpisodes builds the episode listing, related seasons and air schedule
+// of a TV subject from its parsed subject page. With details, the titles,
+// air dates and overviews of the first episodes are read from their own
+// pages.
+func (s *DoubanService) GetEpisodes(page *model.SubjectPage, details bool) *model.EpisodeListing {
+	listing := &model.EpisodeListing{
+		SubjectID: page.ID,
+		Seasons:   page.Seasons,
+		IMDbID:    page.IMDbID,
+		Schedule: model.EpisodeSchedule{
+			Premiere:        page.ReleaseDates,
+			EpisodesCount:   page.EpisodesCount,
+			EpisodeDuration: page.EpisodeDuration,
+		},
+	}
+	// 季选择器缺失时（如单季页面）从标题 "名称 第二季" 推断季数
+	_, listing.SeasonNumber = splitSeasonTitle(page.Title)
+	for _, season := range listing.Seasons {
+		if season.Current {
+			listing.SeasonNumber = season.Number
+		}
+	}
+
+	if numbers := page.EpisodeNumbers; len(numbers) > 0 {
+		listing.Episodes = make([]model.Episode, len(numbers))
+		for i, n := range numbers {
+			listing.Episodes[i] = model.Episode{Number: n}
+		}
+		if details {
+			s.fillEpisodeDetails(page.ID, listing.Episodes)
+		}
+	} else {
+		// 没有分集页面时按集数生成编号，供 TMDB 补充
+		listing.Episodes = make([]model.Episode, listing.Schedule.EpisodesCount)
+		for i := range listing.Episodes {
+			listing.Episodes[i] = model.Episode{Number: i + 1}
+		}
+	}
+
+	UpdateSchedule(listing, time.Now())
+
+	log.Debug().Str("id", page.ID).Int("episodes", len(listing.Episodes)).Int("seasons", len(listing.Seasons)).Msg("Built episode listing")
+
+	return listing
+}
+
+// fillEpisodeDetails fetches the pages of up to maxEpisodePages episodes,
+// one at a time. Failures leave the episode with its number only.
+func (s *DoubanService) fillEpisodeDetails(subjectID string, episodes []model.Episode) {
+	for i := range episodes {
+		if i >= maxEpisodePages {
+			break
+		}
+		if i > 0 {
+			time.Sleep(episodePageDelay)
+		}
+
+		ep := &episodes[i]
+		u := fmt.Sprintf("https://movie.douban.com/subject/%s/episode/%d/", subjectID, ep.Number)
+		data, err := s.client.Fetch(u)
+		if err != nil {
+			log.Debug().Err(err).Str("id", subjectID).Int("episode", ep.Number).Msg("Failed to fetch episode page")
+			continue
+		}
+		parseEpisodePage(string(data), ep)
+	}
+}
+
+// UpdateSchedule recomputes the aired count and next episode from the
+// episode air dates. Cached listings are updated on every response.
+func UpdateSchedule(listing *model.EpisodeListing, now time.Time) {
+	today := now.Format("2006-01-02")
+
+	listing.Schedule.Aired = 0
+	listing.Schedule.NextEpisode = nil
+	for i := range listing.Episodes {
+		ep := &listing.Episodes[i]
+		if ep.AirDate == "" {
+			continue
+		}
+		if ep.AirDate <= today {
+			listing.Schedule.Aired++
+		} else if listing.Schedule.NextEpisode == nil {
+			next := *ep
+			listing.Schedule.NextEpisode = &next
+		}
+	}
+}
+
+// parseSeasons reads the season selector of a multi-season show
+func parseSeasons(page string) []model.Season {
+	var seasons []model.Season
+	for _, m := range reSeasonOption.FindAllStringSubmatch(firstMatch(reSeasonSelect, page), -1) {
+		number, _ := strconv.Atoi(m[3])
+		seasons = append(seasons, model.Season{
+			ID:      m[1],
+			Number:  number,
+			Current: strings.Contains(m[2], "selected"),
+		})
+	}
+	return seasons
+}
+
+// parseEpisodeNumbers returns the sorted, unique episode numbers linked
+// from the subject page's episode list
+func parseEpisodeNumbers(page string) []int {
+	seen := make(map[int]bool)
+	var numbers []int
+	for _, m := range reEpisodeLink.FindAllStringSubmatch(page, -1) {
+		n, _ := strconv.Atoi(m[1])
+		if n > 0 && !seen[n] {
+			seen[n] = true
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// parseEpisodePage fills an episode from its page's ep-info list, e.g.
+// "本集中文名: ...", "本集原名: ...", "播放时间: 2019-01-01", "剧情简介: ..."
+func parseEpisodePage(page string, ep *model.Episode) {
+	block := firstMatch(reEpisodeInfo, page)
+	items := parseLabeledItems(block)
+	value := func(label string) string {
+		v := items[label]
+		if reEpisodeAbsent.MatchString(v) {
+			return ""
+		}
+		return v
+	}
+
+	ep.Title = value("本集中文名")
+	ep.OriginalTitle = value("本集原名")
+	ep.AirDate = value("播放时间")
+	ep.Overview = value("剧情简介")
+
+	// 长简介折叠显示，优先使用展开后的完整版本
+	if full := cleanText(firstMatch(reSummaryAll, block)); full != "" {
+		ep.Overview = full
+	}
+}
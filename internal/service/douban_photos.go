@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PhotosPageSize is the fixed page size of Douban photo gallery pages
+const PhotosPageSize = 30
+
+// Photo gallery types
+const (
+	PhotoTypeStill     = "still"
+	PhotoTypePoster    = "poster"
+	PhotoTypeWallpaper = "wallpaper"
+)
+
+// photoTypeCodes maps gallery types to Douban's type parameter
+var photoTypeCodes = map[string]string{
+	PhotoTypeStill:     "S",
+	PhotoTypePoster:    "R",
+	PhotoTypeWallpaper: "W",
+}
+
+var (
+	rePhotoSize  = regexp.MustCompile(`/view/photo/[a-z_]+/public/`)
+	rePhotoID    = regexp.MustCompile(`data-id="(\d+)"`)
+	rePhotoImg   = regexp.MustCompile(`<img[^>]+src="([^"]+)"`)
+	rePhotoProp  = regexp.MustCompile(`(?s)<div class="prop">\s*(\d+)\s*x\s*(\d+)\s*</div>`)
+	rePhotoName  = regexp.MustCompile(`(?s)<div class="name">(.*?)(?:<a|</div>)`)
+	rePhotoTotal = regexp.MustCompile(`共\s*(\d+)\s*张`)
+	rePhotoWebp  = regexp.MustCompile(`\.webp$`)
+)
+
+// IsPhotoType reports whether t is a supported gallery type
+func IsPhotoType(t string) bool {
+	_, ok := photoTypeCodes[t]
+	return ok
+}
+
+// GetPhotoGallery fetches count photos of a type starting at offset start.
+// Douban serves the gallery in fixed pages, so a window crossing a page
+// boundary takes two requests.
+func (s *DoubanService) GetPhotoGallery(subjectID, photoType string, start, count int) (*model.PhotosPage, error) {
+	if !model.IsDoubanID(subjectID) {
+		return nil, fmt.Errorf("invalid subject id: %q", subjectID)
+	}
+	code, ok := photoTypeCodes[photoType]
+	if !ok {
+		return nil, fmt.Errorf("invalid photo type: %q", photoType)
+	}
+	if count <= 0 || count > PhotosPageSize {
+		count = PhotosPageSize
+	}
+
+	result := &model.PhotosPage{Photos: []model.Photo{}}
+	pageStart := start - start%PhotosPageSize
+
+	for pageStart < start+count {
+		u := fmt.Sprintf("https://movie.douban.com/subject/%s/photos?type=%s&start=%d&sortby=like&size=a&subtype=a",
+			subjectID, code, pageStart)
+
+		data, err := s.client.Fetch(u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch photos: %w", err)
+		}
+
+		html := string(data)
+		photos := parsePhotoGallery(html)
+		if pageStart == 0 && len(photos) == 0 && !strings.Contains(html, "poster-col") {
+			s.recordDrift("photos", "no photo list found", data)
+			return nil, fmt.Errorf("failed to parse photos: %w", ErrInvalidPayload)
+		}
+		result.Total = parseCount(firstMatch(rePhotoTotal, html))
+
+		for i, photo := range photos {
+			if pos := pageStart + i; pos >= start && pos < start+count {
+				result.Photos = append(result.Photos, photo)
+			}
+		}
+
+		if len(photos) < PhotosPageSize {
+			break
+		}
+		pageStart += PhotosPageSize
+	}
+
+	result.HasMore = start+len(result.Photos) < result.Total
+
+	log.Debug().Str("id", subjectID).Str("type", photoType).Int("count", len(result.Photos)).Msg("Fetched photos")
+
+	return result, nil
+}
+
+// parsePhotoGallery extracts the photos of a gallery page
+func parsePhotoGallery(page string) []model.Photo {
+	var photos []model.Photo
+
+	for _, block := range splitBlocks(page, "<li ") {
+		id := firstMatch(rePhotoID, block)
+		img := firstMatch(rePhotoImg, block)
+		if id == "" || img == "" {
+			continue
+		}
+
+		photo := newPhoto(id, img)
+		if m := rePhotoProp.FindStringSubmatch(block); m != nil {
+			photo.Width = parseCount(m[1])
+			photo.Height = parseCount(m[2])
+		}
+		photo.Description = cleanText(firstMatch(rePhotoName, block))
+
+		photos = append(photos, photo)
+	}
+
+	return photos
+}
+
+// newPhoto builds a photo with every size variant derived from one image URL,
+// e.g. https://img9.doubanio.com/view/photo/m/public/p2561716440.webp
+func newPhoto(id, image string) model.Photo {
+	sizes := photoSizes(image)
+	if sizes == nil {
+		return model.Photo{ID: id, Image: image, Thumb: image}
+	}
+	return model.Photo{
+		ID:    id,
+		Image: sizes.Large,
+		Thumb: sizes.Thumb,
+		Sizes: sizes,
+	}
+}
+
+// photoSizes rewrites the size segment of a Douban photo URL.
+// Returns nil for URLs that don't follow the /view/photo/<size>/ layout.
+func photoSizes(image string) *model.PhotoSizes {
+	if !rePhotoSize.MatchString(image) {
+		return nil
+	}
+	variant := func(size string) string {
+		return rePhotoSize.ReplaceAllString(image, "/view/photo/"+size+"/public/")
+	}
+	return &model.PhotoSizes{
+		Thumb:  variant("s"),
+		Medium: variant("m"),
+		Large:  variant("l"),
+		Raw:    rePhotoWebp.ReplaceAllString(variant("raw"), ".jpg"), // 原图只提供 jpg
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"kerkerker-douban-service/internal/model"
+)
+
+var (
+	reJSONLD      = regexp.MustCompile(`(?s)<script type="application/ld\+json">(.*?)</script>`)
+	rePageTitle   = regexp.MustCompile(`(?s)<title>\s*(.*?)\s*\(豆瓣\)\s*</title>`)
+	reInfoBlock   = regexp.MustCompile(`(?s)<div id="info"[^>]*>(.*?)</div>`)
+	reSummaryAll  = regexp.MustCompile(`(?s)<span class="all hidden"[^>]*>(.*?)</span>`)
+	reSummary     = regexp.MustCompile(`(?s)<span property="v:summary"[^>]*>(.*?)</span>`)
+	reVotes       = regexp.MustCompile(`<span property="v:votes">(\d+)</span>`)
+	reRatingPer   = regexp.MustCompile(`<span class="rating_per">([\d.]+)%</span>`)
+	reHref        = regexp.MustCompile(`href="([^"]+)"`)
+	reDateRegion  = regexp.MustCompile(`^(.+?)\s*\((.+)\)$`)
+	reIMDbID      = regexp.MustCompile(`tt\d+`)
+	jsonLDEscaper = strings.NewReplacer("\n", " ", "\r", " ", "\t", " ")
+)
+
+// subjectJSONLD is the schema.org block embedded in subject pages
+type subjectJSONLD struct {
+	Name            string   `json:"name"`
+	DatePublished   string   `json:"datePublished"`
+	Genre           []string `json:"genre"`
+	Description     string   `json:"description"`
+	AggregateRating struct {
+		RatingCount json.RawMessage `json:"ratingCount"`
+	} `json:"aggregateRating"`
+}
+
+// GetSubjectPage fetches and parses the subject HTML page for extended fields
+func (s *DoubanService) GetSubjectPage(subjectID string) (*model.SubjectPage, error) {
+	if !model.IsDoubanID(subjectID) {
+		return nil, fmt.Errorf("invalid subject id: %q", subjectID)
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/subject/%s/", subjectID)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subject page: %w", err)
+	}
+
+	page := parseSubjectPage(subjectID, string(data))
+	if page == nil {
+		s.recordDrift("subject_page", "no info block or json-ld", data)
+		return nil, fmt.Errorf("failed to parse subject page: %w", ErrInvalidPayload)
+	}
+
+	return page, nil
+}
+
+// parseSubjectPage extracts the extended fields from a subject page.
+// Returns nil when neither the #info block nor the JSON-LD block is found.
+func parseSubjectPage(subjectID, html string) *model.SubjectPage {
+	info := parseInfo(html)
+	ld, ldErr := parseSubjectJSONLD(html)
+	if len(info) == 0 && ldErr != nil {
+		return nil
+	}
+
+	page := &model.SubjectPage{
+		ID:           subjectID,
+		Title:        cleanText(firstMatch(rePageTitle, html)),
+		Aliases:      splitList(cleanText(info["又名"]), "/"),
+		Genres:       splitList(cleanText(info["类型"]), "/"),
+		Languages:    splitList(cleanText(info["语言"]), "/"),
+		Countries:    splitList(cleanText(info["制片国家/地区"]), "/"),
+		ReleaseDates: parseReleaseDates(info),
+		IMDbID:       reIMDbID.FindString(cleanText(info["IMDb"])),
+		Votes:        parseCount(firstMatch(reVotes, html)),
+		OfficialSite: firstMatch(reHref, info["官方网站"]),
+		Writers:      splitList(cleanText(info["编剧"]), "/"),
+		Directors:    parseCelebrityRefs(info["导演"]),
+		Actors:       parseCelebrityRefs(info["主演"]),
+
+		EpisodesCount:   parseCount(info["集数"]),
+		EpisodeDuration: cleanText(info["单集片长"]),
+		EpisodeNumbers:  parseEpisodeNumbers(html),
+		Seasons:         parseSeasons(html),
+	}
+
+	// 简介：优先使用展开后的完整版本
+	if full := firstMatch(reSummaryAll, html); full != "" {
+		page.Synopsis = cleanMultiline(full)
+	} else {
+		page.Synopsis = cleanMultiline(firstMatch(reSummary, html))
+	}
+
+	// 评分分布按 5 星到 1 星的顺序排列
+	if shares := reRatingPer.FindAllStringSubmatch(html, 5); len(shares) == 5 {
+		page.RatingDistribution = make(map[string]float64, 5)
+		for i, m := range shares {
+			share, _ := strconv.ParseFloat(m[1], 64)
+			page.RatingDistribution[strconv.Itoa(5-i)] = share
+		}
+	}
+
+	// JSON-LD 作为 #info 缺失字段的补充
+	if ldErr == nil {
+		if page.Synopsis == "" {
+			page.Synopsis = strings.TrimSpace(ld.Description)
+		}
+		if len(page.Genres) == 0 {
+			page.Genres = ld.Genre
+		}
+		if page.Votes == 0 {
+			page.Votes = parseCount(string(ld.AggregateRating.RatingCount))
+		}
+		if len(page.ReleaseDates) == 0 && ld.DatePublished != "" {
+			page.ReleaseDates = []model.ReleaseDate{{Date: ld.DatePublished}}
+		}
+	}
+
+	return page
+}
+
+// parseSubjectJSONLD decodes the JSON-LD block. Douban emits raw control
+// characters inside strings, which are replaced before decoding.
+func parseSubjectJSONLD(html string) (*subjectJSONLD, error) {
+	raw := firstMatch(reJSONLD, html)
+	if raw == "" {
+		return nil, fmt.Errorf("json-ld block not found")
+	}
+
+	var ld subjectJSONLD
+	if err := json.Unmarshal([]byte(jsonLDEscaper.Replace(raw)), &ld); err != nil {
+		return nil, fmt.Errorf("invalid json-ld: %w", err)
+	}
+	return &ld, nil
+}
+
+// parseInfo splits the #info block into label -> raw HTML value pairs,
+// e.g. "语言" -> " 英语"
+func parseInfo(html string) map[string]string {
+	block := firstMatch(reInfoBlock, html)
+	if block == "" {
+		return nil
+	}
+
+	fields := make(map[string]string)
+	for _, line := range reBR.Split(block, -1) {
+		idx := labelSeparator(line)
+		if idx < 0 {
+			continue
+		}
+		label := strings.TrimSpace(cleanText(line[:idx]))
+		if label != "" {
+			fields[label] = line[idx+1:]
+		}
+	}
+	return fields
+}
+
+// labelSeparator returns the index of the first ':' outside of an HTML tag
+func labelSeparator(line string) int {
+	inTag := false
+	for i, c := range line {
+		switch {
+		case c == '<':
+			inTag = true
+		case c == '>':
+			inTag = false
+		case c == ':' && !inTag:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseReleaseDates parses "1994-09-10(多伦多电影节) / 1994-10-14(美国)".
+// TV subjects use 首播 instead of 上映日期.
+func parseReleaseDates(info map[string]string) []model.ReleaseDate {
+	value := info["上映日期"]
+	if value == "" {
+		value = info["首播"]
+	}
+
+	var dates []model.ReleaseDate
+	for _, entry := range splitList(cleanText(value), "/") {
+		if m := reDateRegion.FindStringSubmatch(entry); m != nil {
+			dates = append(dates, model.ReleaseDate{Date: m[1], Region: m[2]})
+		} else {
+			dates = append(dates, model.ReleaseDate{Date: entry})
+		}
+	}
+	return dates
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// Top250PageSize is the number of items per Top 250 page
+	Top250PageSize = 25
+	// Top250Pages is the number of pages in the Top 250 list
+	Top250Pages = 10
+)
+
+var (
+	reTop250Rank   = regexp.MustCompile(`<em[^>]*>(\d+)</em>`)
+	reTop250Cover  = regexp.MustCompile(`<img[^>]+src="([^"]+)"`)
+	reTop250Title  = regexp.MustCompile(`<span class="title">([^<]*)</span>`)
+	reTop250Other  = regexp.MustCompile(`<span class="other">([^<]*)</span>`)
+	reTop250Info   = regexp.MustCompile(`(?s)<div class="bd">\s*<p[^>]*>(.*?)</p>`)
+	reTop250Rating = regexp.MustCompile(`<span class="rating_num"[^>]*>([\d.]+)</span>`)
+	reTop250Votes  = regexp.MustCompile(`<span>(\d+)人评价</span>`)
+	reTop250Quote  = regexp.MustCompile(`(?s)<p class="quote">(.*?)</p>`)
+)
+
+// GetTop250 fetches one page (1-10) of the Douban Top 250 list
+func (s *DoubanService) GetTop250(page int) ([]model.Top250Item, error) {
+	if page < 1 || page > Top250Pages {
+		return nil, fmt.Errorf("top250 page out of range: %d", page)
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/top250?start=%d&filter=", (page-1)*Top250PageSize)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch top250: %w", err)
+	}
+
+	items := parseTop250(string(data))
+	if len(items) == 0 {
+		s.recordDrift("top250", "no items parsed", data)
+		return nil, fmt.Errorf("failed to parse top250: %w", ErrInvalidPayload)
+	}
+
+	log.Debug().Int("page", page).Int("count", len(items)).Msg("Fetched top250")
+
+	return items, nil
+}
+
+// parseTop250 extracts the list items from a Top 250 page
+func parseTop250(page string) []model.Top250Item {
+	var items []model.Top250Item
+
+	for _, block := range splitBlocks(page, `<div class="item">`) {
+		id := firstMatch(reSubjectID, block)
+		titles := reTop250Title.FindAllStringSubmatch(block, -1)
+		if id == "" || len(titles) == 0 {
+			continue
+		}
+
+		item := model.Top250Item{
+			Rank:  parseCount(firstMatch(reTop250Rank, block)),
+			ID:    id,
+			Title: cleanText(titles[0][1]),
+			Cover: firstMatch(reTop250Cover, block),
+			URL:   fmt.Sprintf("https://movie.douban.com/subject/%s/", id),
+			Rate:  firstMatch(reTop250Rating, block),
+			Votes: parseCount(firstMatch(reTop250Votes, block)),
+			Quote: cleanText(firstMatch(reTop250Quote, block)),
+		}
+		if len(titles) > 1 {
+			item.OriginalTitle = strings.TrimPrefix(cleanText(titles[1][1]), "/ ")
+		}
+		if other := cleanText(firstMatch(reTop250Other, block)); other != "" {
+			item.OtherTitles = splitList(strings.TrimPrefix(other, "/"), "/")
+		}
+
+		// 导演: xxx 主演: xxx<br>1994 / 美国 / 犯罪 剧情
+		lines := strings.Split(cleanMultiline(firstMatch(reTop250Info, block)), "\n")
+		if len(lines) > 0 {
+			item.Credits = lines[0]
+		}
+		if len(lines) > 1 {
+			parts := splitList(lines[len(lines)-1], "/")
+			if len(parts) > 0 {
+				item.Year = reDigits.FindString(parts[0])
+			}
+			if len(parts) > 1 {
+				item.Region = parts[1]
+			}
+			if len(parts) > 2 {
+				item.Genres = strings.Fields(parts[2])
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
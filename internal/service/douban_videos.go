@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Video types shared by Douban and TMDB videos
+const (
+	VideoTypeTrailer    = "trailer"
+	VideoTypeClip       = "clip"
+	VideoTypeFeaturette = "featurette"
+	VideoTypeOther      = "other"
+)
+
+// trailerSourceWorkers bounds concurrent trailer page requests
+const trailerSourceWorkers = 3
+
+// TrailerSourceTTL bounds how long resolved trailer sources are cached.
+// Douban signs the mp4 URLs with a time-limited auth key.
+const TrailerSourceTTL = time.Hour
+
+var (
+	reVideoSection  = regexp.MustCompile(`(?s)<h2[^>]*>(.*?)</h2>`)
+	reTrailerID     = regexp.MustCompile(`/trailer/(\d+)/`)
+	reTrailerCover  = regexp.MustCompile(`<img[^>]+src="([^"]+)"`)
+	reTrailerLength = regexp.MustCompile(`<em>\s*([\d:]+)\s*</em>`)
+	reTrailerTitle  = regexp.MustCompile(`(?s)<p>\s*<a[^>]*>(.*?)</a>`)
+	reTrailerDate   = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	reTrailerSource = regexp.MustCompile(`<source[^>]+src="([^"]+)"`)
+)
+
+// doubanVideoSections maps trailer page headings to video types
+var doubanVideoSections = []struct {
+	heading   string
+	videoType string
+}{
+	{"预告片", VideoTypeTrailer},
+	{"片段", VideoTypeClip},
+	{"花絮", VideoTypeFeaturette},
+}
+
+// GetTrailers fetches the trailers and clips of a subject. The playable
+// source is resolved for the first resolve videos only, as each one needs
+// its own page request.
+func (s *DoubanService) GetTrailers(subjectID string, resolve int) ([]model.Video, error) {
+	videos, err := s.fetchTrailerList(subjectID)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveTrailerSources(videos[:min(resolve, len(videos))])
+	return videos, nil
+}
+
+// GetPlayableTrailer returns the first trailer (not clip or featurette) of a
+// subject whose playable source resolves, trying at most candidates
+// trailers. Returns nil without an error when none is playable.
+func (s *DoubanService) GetPlayableTrailer(subjectID string, candidates int) (*model.Video, error) {
+	videos, err := s.fetchTrailerList(subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tried := 0
+	for i := range videos {
+		if videos[i].Type != VideoTypeTrailer {
+			continue
+		}
+		if tried >= candidates {
+			break
+		}
+		tried++
+
+		s.resolveTrailerSources(videos[i : i+1])
+		if videos[i].Source != "" {
+			return &videos[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// fetchTrailerList fetches the trailer page of a subject without resolving sources
+func (s *DoubanService) fetchTrailerList(subjectID string) ([]model.Video, error) {
+	if !model.IsDoubanID(subjectID) {
+		return nil, fmt.Errorf("invalid subject id: %q", subjectID)
+	}
+
+	u := fmt.Sprintf("https://movie.douban.com/subject/%s/trailer", subjectID)
+
+	data, err := s.client.Fetch(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trailers: %w", err)
+	}
+
+	videos := parseTrailers(string(data))
+
+	log.Debug().Str("id", subjectID).Int("count", len(videos)).Msg("Fetched trailers")
+
+	return videos, nil
+}
+
+// resolveTrailerSources fills the mp4 source of each video from its page
+func (s *DoubanService) resolveTrailerSources(videos []model.Video) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, trailerSourceWorkers)
+
+	for i := range videos {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(v *model.Video) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			data, err := s.client.Fetch(v.URL)
+			if err != nil {
+				log.Debug().Err(err).Str("trailer", v.ID).Msg("Failed to fetch trailer page")
+				return
+			}
+			v.Source = firstMatch(reTrailerSource, string(data))
+		}(&videos[i])
+	}
+
+	wg.Wait()
+}
+
+// parseTrailers extracts the videos of a subject trailer page, typed by the
+// section heading they appear under
+func parseTrailers(page string) []model.Video {
+	videos := []model.Video{}
+	seen := make(map[string]bool)
+
+	sections := reVideoSection.FindAllStringSubmatchIndex(page, -1)
+	for i, loc := range sections {
+		end := len(page)
+		if i+1 < len(sections) {
+			end = sections[i+1][0]
+		}
+		videoType := doubanVideoType(cleanText(page[loc[2]:loc[3]]))
+
+		for _, block := range splitBlocks(page[loc[1]:end], "<li") {
+			id := firstMatch(reTrailerID, block)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			videos = append(videos, model.Video{
+				ID:          id,
+				Title:       cleanText(firstMatch(reTrailerTitle, block)),
+				Type:        videoType,
+				Duration:    parseClock(firstMatch(reTrailerLength, block)),
+				Cover:       firstMatch(reTrailerCover, block),
+				URL:         fmt.Sprintf("https://movie.douban.com/trailer/%s/", id),
+				Provider:    "douban",
+				PublishedAt: reTrailerDate.FindString(block),
+			})
+		}
+	}
+
+	return videos
+}
+
+func doubanVideoType(heading string) string {
+	for _, section := range doubanVideoSections {
+		if strings.Contains(heading, section.heading) {
+			return section.videoType
+		}
+	}
+	return VideoTypeOther
+}
+
+// parseClock converts "mm:ss" or "hh:mm:ss" to seconds
+func parseClock(s string) int {
+	seconds := 0
+	for _, part := range strings.Split(s, ":") {
+		seconds = seconds*60 + parseCount(part)
+	}
+	return seconds
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Helpers for scraping Douban HTML pages. Douban pages are server-rendered
+// with a stable class naming, so targeted regular expressions are enough.
+
+var (
+	reTag        = regexp.MustCompile(`(?s)<[^>]*>`)
+	reBR         = regexp.MustCompile(`(?i)<br\s*/?>`)
+	reSpaces     = regexp.MustCompile(`[ \t\r\f\v]+`)
+	reBlankLines = regexp.MustCompile(`\n\s*\n+`)
+	reSubjectID  = regexp.MustCompile(`/subject/(\d+)`)
+	reDigits     = regexp.MustCompile(`\d+`)
+	reListItem   = regexp.MustCompile(`(?s)<li[^>]*>(.*?)</li>`)
+)
+
+// cleanText unescapes entities, strips tags and collapses whitespace
+func cleanText(s string) string {
+	s = reTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	s = reSpaces.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
+
+// cleanMultiline is like cleanText but keeps <br> and paragraph breaks
+func cleanMultiline(s string) string {
+	s = reBR.ReplaceAllString(s, "\n")
+	s = strings.ReplaceAll(s, "</p>", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = cleanText(line)
+	}
+	s = strings.Join(lines, "\n")
+	s = reBlankLines.ReplaceAllString(s, "\n")
+	return strings.TrimSpace(s)
+}
+
+// firstMatch returns the first capture group of re in s, or ""
+func firstMatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+// splitBlocks splits s at every occurrence of marker, dropping the leading
+// part before the first marker
+func splitBlocks(s, marker string) []string {
+	parts := strings.Split(s, marker)
+	if len(parts) <= 1 {
+		return nil
+	}
+	return parts[1:]
+}
+
+// splitList splits a "a / b / c" style list and trims each entry
+func splitList(s, sep string) []string {
+	var result []string
+	for _, part := range strings.Split(s, sep) {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// parseCount extracts the first integer from s (e.g. "123人评价" -> 123)
+func parseCount(s string) int {
+	n, _ := strconv.Atoi(reDigits.FindString(s))
+	return n
+}
+
+// parseLabeledItems reads a list of "<li>label: value</li>" items as plain
+// text, accepting both ASCII and full-width colons
+func parseLabeledItems(block string) map[string]string {
+	items := make(map[string]string)
+	for _, m := range reListItem.FindAllStringSubmatch(block, -1) {
+		text := cleanText(m[1])
+		label, value, ok := strings.Cut(strings.Replace(text, "：", ":", 1), ":")
+		if !ok {
+			continue
+		}
+		items[strings.TrimSpace(label)] = strings.TrimSpace(value)
+	}
+	return items
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Metadata fields, each with its own provider order
+const (
+	MetadataFieldBackdrop       = "backdrop"
+	MetadataFieldImages         = "images"
+	MetadataFieldCredits        = "credits"
+	MetadataFieldExternalIDs    = "external_ids"
+	MetadataFieldWatchProviders = "watch_providers"
+	MetadataFieldOverview       = "overview"
+	MetadataFieldTagline        = "tagline"
+	MetadataFieldGenres         = "genres"
+)
+
+// metadataFields lists every field for validating the configured order
+var metadataFields = []string{
+	MetadataFieldBackdrop,
+	MetadataFieldImages,
+	MetadataFieldCredits,
+	MetadataFieldExternalIDs,
+	MetadataFieldWatchProviders,
+	MetadataFieldOverview,
+	MetadataFieldTagline,
+	MetadataFieldGenres,
+}
+
+// MetadataSubject identifies the Douban subject to look up
+type MetadataSubject struct {
+	DoubanID  string
+	Title     string
+	Year      string
+	IsTV      bool
+	IMDbID    func() string        // 按需获取 IMDb 编号，可为 nil
+	Directors []model.CelebrityRef // 用于对应演职员
+	Actors    []model.CelebrityRef
+}
+
+// MetadataProvider supplies external metadata for Douban subjects. Each
+// method returns nil (or "") without an error when the provider has
+// nothing for the subject, so the next provider is asked.
+type MetadataProvider interface {
+	Name() string
+	Backdrop(ctx context.Context, subject MetadataSubject) (string, error)
+	Images(ctx context.Context, subject MetadataSubject) (*model.Artwork, error)
+	Credits(ctx context.Context, subject MetadataSubject) (*model.Credits, error)
+	ExternalIDs(ctx context.Context, subject MetadataSubject) (*model.ExternalIDs, error)
+	WatchProviders(ctx context.Context, subject MetadataSubject, region string) (*model.WatchProviders, error)
+	Text(ctx context.Context, subject MetadataSubject) (*model.LocalizedText, error)
+}
+
+// MetadataChain asks providers in a per-field order and returns the first
+// result, together with the name of the provider that supplied it.
+// Provider errors are logged and the next provider is tried.
+type MetadataChain struct {
+	order map[string][]MetadataProvider
+}
+
+// NewMetadataChain creates a MetadataChain. order maps a field to provider
+// names; fields without an order ask every provider in the given order.
+func NewMetadataChain(providers []MetadataProvider, order map[string][]string) (*MetadataChain, error) {
+	byName := make(map[string]MetadataProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	isField := make(map[string]bool, len(metadataFields))
+	for _, field := range metadataFields {
+		isField[field] = true
+	}
+	for field := range order {
+		if !isField[field] {
+			return nil, fmt.Errorf("unknown metadata field: %s", field)
+		}
+	}
+
+	chain := &MetadataChain{order: make(map[string][]MetadataProvider)}
+	for _, field := range metadataFields {
+		names, ok := order[field]
+		if !ok {
+			chain.order[field] = providers
+			continue
+		}
+		for _, name := range names {
+			p, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown metadata provider %q for %s", name, field)
+			}
+			chain.order[field] = append(chain.order[field], p)
+		}
+	}
+	return chain, nil
+}
+
+// Backdrop returns the first backdrop URL
+func (c *MetadataChain) Backdrop(ctx context.Context, subject MetadataSubject) (string, string) {
+	for _, p := range c.order[MetadataFieldBackdrop] {
+		url, err := p.Backdrop(ctx, subject)
+		if err != nil {
+			logProviderError(p, MetadataFieldBackdrop, subject, err)
+			continue
+		}
+		if url != "" {
+			return url, p.Name()
+		}
+	}
+	return "", ""
+}
+
+// Images returns the first non-empty list of each image type, so one
+// provider's backdrops can be combined with another's posters and logos
+func (c *MetadataChain) Images(ctx context.Context, subject MetadataSubject) (*model.Artwork, string) {
+	return c.mergeImages(subject, func(p MetadataProvider) *model.Artwork {
+		artwork, err := p.Images(ctx, subject)
+		if err != nil {
+			logProviderError(p, MetadataFieldImages, subject, err)
+		}
+		return artwork
+	})
+}
+
+// ImagesAndBackdrop returns the result of Images together with a backdrop
+// URL and its provider, asking each provider for its images only once. A
+// provider's backdrop is the original size of its first backdrop image.
+func (c *MetadataChain) ImagesAndBackdrop(ctx context.Context, subject MetadataSubject) (*model.Artwork, string, string) {
+	fetched := make(map[MetadataProvider]*model.Artwork)
+	images := func(p MetadataProvider) *model.Artwork {
+		if artwork, ok := fetched[p]; ok {
+			return artwork
+		}
+		artwork, err := p.Images(ctx, subject)
+		if err != nil {
+			logProviderError(p, MetadataFieldImages, subject, err)
+		}
+		fetched[p] = artwork
+		return artwork
+	}
+
+	// 先取背景图，合并图片时会修改各提供方的结果
+	var backdrop, backdropSource string
+	for _, p := range c.order[MetadataFieldBackdrop] {
+		if url := originalBackdrop(images(p)); url != "" {
+			backdrop, backdropSource = url, p.Name()
+			break
+		}
+	}
+
+	artwork, _ := c.mergeImages(subject, images)
+	return artwork, backdrop, backdropSource
+}
+
+// originalBackdrop returns the original size (or largest listed) URL of
+// the first backdrop
+func originalBackdrop(artwork *model.Artwork) string {
+	if artwork == nil || len(artwork.Backdrops) == 0 {
+		return ""
+	}
+	var url string
+	for _, v := range artwork.Backdrops[0].Variants {
+		url = v.URL
+		if v.Size == "original" {
+			break
+		}
+	}
+	return url
+}
+
+// mergeImages combines the images of the providers in order, reading each
+// provider's images through images
+func (c *MetadataChain) mergeImages(subject MetadataSubject, images func(MetadataProvider) *model.Artwork) (*model.Artwork, string) {
+	var result *model.Artwork
+	var source string
+	for _, p := range c.order[MetadataFieldImages] {
+		artwork := images(p)
+		if artwork == nil {
+			continue
+		}
+		if result == nil {
+			result, source = artwork, p.Name()
+			result.DoubanID = subject.DoubanID
+		} else {
+			if result.TMDBID == 0 {
+				result.TMDBID, result.MediaType = artwork.TMDBID, artwork.MediaType
+			}
+			if len(result.Backdrops) == 0 {
+				result.Backdrops = artwork.Backdrops
+			}
+			if len(result.Posters) == 0 {
+				result.Posters = artwork.Posters
+			}
+			if len(result.Logos) == 0 {
+				result.Logos = artwork.Logos
+			}
+		}
+		if len(result.Backdrops) > 0 && len(result.Posters) > 0 && len(result.Logos) > 0 {
+			break
+		}
+	}
+	return result, source
+}
+
+// Credits returns the first cast and crew
+func (c *MetadataChain) Credits(ctx context.Context, subject MetadataSubject) (*model.Credits, string) {
+	for _, p := range c.order[MetadataFieldCredits] {
+		credits, err := p.Credits(ctx, subject)
+		if err != nil {
+			logProviderError(p, MetadataFieldCredits, subject, err)
+			continue
+		}
+		if credits != nil {
+			return credits, p.Name()
+		}
+	}
+	return nil, ""
+}
+
+// ExternalIDs returns the external IDs, filling fields a provider left
+// empty from the following providers
+func (c *MetadataChain) ExternalIDs(ctx context.Context, subject MetadataSubject) (*model.ExternalIDs, string) {
+	var result *model.ExternalIDs
+	var source string
+	for _, p := range c.order[MetadataFieldExternalIDs] {
+		ids, err := p.ExternalIDs(ctx, subject)
+		if err != nil {
+			logProviderError(p, MetadataFieldExternalIDs, subject, err)
+			continue
+		}
+		if ids == nil {
+			continue
+		}
+		if result == nil {
+			result, source = ids, p.Name()
+			result.DoubanID = subject.DoubanID
+			continue
+		}
+		result.Fill(ids)
+	}
+	return result, source
+}
+
+// WatchProviders returns the first provider list of a region
+func (c *MetadataChain) WatchProviders(ctx context.Context, subject MetadataSubject, region string) (*model.WatchProviders, string) {
+	for _, p := range c.order[MetadataFieldWatchProviders] {
+		providers, err := p.WatchProviders(ctx, subject, region)
+		if err != nil {
+			logProviderError(p, MetadataFieldWatchProviders, subject, err)
+			continue
+		}
+		if providers != nil {
+			return providers, p.Name()
+		}
+	}
+	return nil, ""
+}
+
+// Text returns the overview, tagline and genres, each from the first
+// provider that has it, and the provider name of each field that was found
+func (c *MetadataChain) Text(ctx context.Context, subject MetadataSubject) (*model.LocalizedText, map[string]string) {
+	result := &model.LocalizedText{}
+	sources := make(map[string]string)
+
+	// 每个提供方最多请求一次，供多个字段共用
+	fetched := make(map[MetadataProvider]*model.LocalizedText)
+	text := func(p MetadataProvider) *model.LocalizedText {
+		if t, ok := fetched[p]; ok {
+			return t
+		}
+		t, err := p.Text(ctx, subject)
+		if err != nil {
+			logProviderError(p, "text", subject, err)
+		}
+		fetched[p] = t
+		return t
+	}
+
+	for _, p := range c.order[MetadataFieldOverview] {
+		if t := text(p); t != nil && t.Overview != "" {
+			result.Overview, sources[MetadataFieldOverview] = t.Overview, p.Name()
+			break
+		}
+	}
+	for _, p := range c.order[MetadataFieldTagline] {
+		if t := text(p); t != nil && t.Tagline != "" {
+			result.Tagline, sources[MetadataFieldTagline] = t.Tagline, p.Name()
+			break
+		}
+	}
+	for _, p := range c.order[MetadataFieldGenres] {
+		if t := text(p); t != nil && len(t.Genres) > 0 {
+			result.Genres, sources[MetadataFieldGenres] = t.Genres, p.Name()
+			break
+		}
+	}
+	return result, sources
+}
+
+func logProviderError(p MetadataProvider, field string, subject MetadataSubject, err error) {
+	log.Warn().Err(err).Str("provider", p.Name()).Str("field", field).Str("id", subject.DoubanID).Msg("Metadata provider failed")
+}
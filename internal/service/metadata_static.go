@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"kerkerker-douban-service/internal/model"
+)
+
+// staticOverride is one entry of the metadata overrides file
+type staticOverride struct {
+	Backdrop       string                          `json:"backdrop,omitempty"`
+	Poster         string                          `json:"poster,omitempty"`
+	Logo           string                          `json:"logo,omitempty"`
+	IMDbID         string                          `json:"imdb_id,omitempty"`
+	TMDBID         int                             `json:"tmdb_id,omitempty"`
+	MediaType      string                          `json:"media_type,omitempty"`
+	Credits        *model.Credits                  `json:"credits,omitempty"`
+	WatchProviders map[string]model.WatchProviders `json:"watch_providers,omitempty"` // 按地区
+	Overview       string                          `json:"overview,omitempty"`
+	Tagline        string                          `json:"tagline,omitempty"`
+	Genres         []string                        `json:"genres,omitempty"`
+}
+
+// StaticProvider is a MetadataProvider serving hand-maintained overrides
+// from a JSON file keyed by Douban ID
+type StaticProvider struct {
+	overrides map[string]staticOverride
+}
+
+// NewStaticProvider creates a StaticProvider without overrides
+func NewStaticProvider() *StaticProvider {
+	return &StaticProvider{overrides: map[string]staticOverride{}}
+}
+
+// LoadStaticProvider reads a metadata overrides file
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata overrides file: %w", err)
+	}
+
+	overrides := map[string]staticOverride{}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata overrides file: %w", err)
+	}
+	return &StaticProvider{overrides: overrides}, nil
+}
+
+// Count returns the number of subjects with overrides
+func (p *StaticProvider) Count() int {
+	return len(p.overrides)
+}
+
+// Name returns the provider name used in METADATA_PROVIDERS
+func (p *StaticProvider) Name() string {
+	return "static"
+}
+
+// Backdrop returns the override backdrop URL
+func (p *StaticProvider) Backdrop(ctx context.Context, subject MetadataSubject) (string, error) {
+	return p.overrides[subject.DoubanID].Backdrop, nil
+}
+
+// Images returns the override backdrop, poster and logo as single
+// original-size images
+func (p *StaticProvider) Images(ctx context.Context, subject MetadataSubject) (*model.Artwork, error) {
+	o, ok := p.overrides[subject.DoubanID]
+	if !ok || (o.Backdrop == "" && o.Poster == "" && o.Logo == "") {
+		return nil, nil
+	}
+
+	image := func(url string) []model.ArtworkImage {
+		if url == "" {
+			return nil
+		}
+		return []model.ArtworkImage{{Variants: []model.ImageVariant{{Size: "original", URL: url}}}}
+	}
+	return &model.Artwork{
+		TMDBID:    o.TMDBID,
+		MediaType: o.MediaType,
+		Backdrops: image(o.Backdrop),
+		Posters:   image(o.Poster),
+		Logos:     image(o.Logo),
+	}, nil
+}
+
+// Credits returns the override cast and crew
+func (p *StaticProvider) Credits(ctx context.Context, subject MetadataSubject) (*model.Credits, error) {
+	return p.overrides[subject.DoubanID].Credits, nil
+}
+
+// ExternalIDs returns the override IMDb and TMDB IDs
+func (p *StaticProvider) ExternalIDs(ctx context.Context, subject MetadataSubject) (*model.ExternalIDs, error) {
+	o, ok := p.overrides[subject.DoubanID]
+	if !ok || (o.IMDbID == "" && o.TMDBID == 0) {
+		return nil, nil
+	}
+	return &model.ExternalIDs{
+		IMDbID:    o.IMDbID,
+		TMDBID:    o.TMDBID,
+		MediaType: o.MediaType,
+	}, nil
+}
+
+// WatchProviders returns the override providers of a region
+func (p *StaticProvider) WatchProviders(ctx context.Context, subject MetadataSubject, region string) (*model.WatchProviders, error) {
+	providers, ok := p.overrides[subject.DoubanID].WatchProviders[region]
+	if !ok {
+		return nil, nil
+	}
+	providers.Region = region
+	return &providers, nil
+}
+
+// Text returns the override overview, tagline and genres
+func (p *StaticProvider) Text(ctx context.Context, subject MetadataSubject) (*model.LocalizedText, error) {
+	o, ok := p.overrides[subject.DoubanID]
+	if !ok || (o.Overview == "" && o.Tagline == "" && len(o.Genres) == 0) {
+		return nil, nil
+	}
+	return &model.LocalizedText{
+		Overview: o.Overview,
+		Tagline:  o.Tagline,
+		Genres:   o.Genres,
+	}, nil
+}
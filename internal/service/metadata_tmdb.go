@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+)
+
+// TMDBProvider is the MetadataProvider backed by TMDB. Subjects are
+// resolved through the TMDBMatcher, so stored mappings are honoured.
+type TMDBProvider struct {
+	tmdb    *TMDBService
+	matcher *TMDBMatcher
+}
+
+// NewTMDBProvider creates a new TMDBProvider
+func NewTMDBProvider(tmdb *TMDBService, matcher *TMDBMatcher) *TMDBProvider {
+	return &TMDBProvider{
+		tmdb:    tmdb,
+		matcher: matcher,
+	}
+}
+
+// Name returns the provider name used in METADATA_PROVIDERS
+func (p *TMDBProvider) Name() string {
+	return "tmdb"
+}
+
+// match returns the TMDB title of a subject, or nil when TMDB is not
+// configured or the subject can't be matched
+func (p *TMDBProvider) match(ctx context.Context, subject MetadataSubject) (*TMDBMatch, error) {
+	if !p.tmdb.IsConfigured() {
+		return nil, nil
+	}
+
+	mapping, err := p.matcher.Match(ctx, MatchQuery{
+		DoubanID: subject.DoubanID,
+		Title:    subject.Title,
+		Year:     subject.Year,
+		IsTV:     subject.IsTV,
+		IMDbID:   subject.IMDbID,
+	})
+	if err != nil || mapping == nil {
+		return nil, err
+	}
+	return &TMDBMatch{ID: mapping.TMDBID, MediaType: mapping.MediaType}, nil
+}
+
+// Backdrop returns the original-size URL of the top TMDB backdrop
+func (p *TMDBProvider) Backdrop(ctx context.Context, subject MetadataSubject) (string, error) {
+	artwork, err := p.Images(ctx, subject)
+	if err != nil {
+		return "", err
+	}
+	return originalBackdrop(artwork), nil
+}
+
+// Images returns the TMDB backdrops, posters and logos
+func (p *TMDBProvider) Images(ctx context.Context, subject MetadataSubject) (*model.Artwork, error) {
+	match, err := p.match(ctx, subject)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	return p.tmdb.GetImages(*match)
+}
+
+// Credits returns the subject's cast and crew merged with TMDB /credits
+func (p *TMDBProvider) Credits(ctx context.Context, subject MetadataSubject) (*model.Credits, error) {
+	match, err := p.match(ctx, subject)
+	if err != nil || match == nil {
+		return nil, err
+	}
+
+	credits, err := p.tmdb.GetCredits(*match)
+	if err != nil {
+		return nil, err
+	}
+	return p.tmdb.MergeCredits(subject.Directors, subject.Actors, credits), nil
+}
+
+// ExternalIDs returns the TMDB ID and the IDs TMDB links to
+func (p *TMDBProvider) ExternalIDs(ctx context.Context, subject MetadataSubject) (*model.ExternalIDs, error) {
+	match, err := p.match(ctx, subject)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	return p.tmdb.GetExternalIDs(*match)
+}
+
+// WatchProviders returns the TMDB watch providers of a region
+func (p *TMDBProvider) WatchProviders(ctx context.Context, subject MetadataSubject, region string) (*model.WatchProviders, error) {
+	match, err := p.match(ctx, subject)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	return p.tmdb.GetWatchProviders(*match, region)
+}
+
+// Text returns the TMDB overview, tagline and genres in Chinese, falling
+// back to English for the overview and tagline
+func (p *TMDBProvider) Text(ctx context.Context, subject MetadataSubject) (*model.LocalizedText, error) {
+	match, err := p.match(ctx, subject)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	return p.tmdb.GetLocalizedText(*match)
+}
+
+// GetExternalIDs returns the IMDb, TVDB, Wikidata and social IDs of a TMDB title
+func (s *TMDBService) GetExternalIDs(match TMDBMatch) (*model.ExternalIDs, error) {
+	var result struct {
+		IMDbID      string `json:"imdb_id"`
+		TVDBID      int    `json:"tvdb_id"`
+		WikidataID  string `json:"wikidata_id"`
+		FacebookID  string `json:"facebook_id"`
+		InstagramID string `json:"instagram_id"`
+		TwitterID   string `json:"twitter_id"`
+	}
+	key := tmdbCacheKey("external_ids", match.MediaType, strconv.Itoa(match.ID))
+	found, err := s.cached(key, &result, func() (bool, error) {
+		err := s.getJSON(fmt.Sprintf("/%s/%d/external_ids", match.MediaType, match.ID), nil, &result)
+		return err == nil, err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &model.ExternalIDs{
+		IMDbID:     result.IMDbID,
+		TMDBID:     match.ID,
+		MediaType:  match.MediaType,
+		TVDBID:     result.TVDBID,
+		WikidataID: result.WikidataID,
+		Facebook:   result.FacebookID,
+		Instagram:  result.InstagramID,
+		Twitter:    result.TwitterID,
+	}, nil
+}
@@ -2,52 +2,58 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"net/url"
 	"strings"
-	"sync/atomic"
+	"sync"
 	"time"
 
+	"kerkerker-douban-service/internal/repository"
+
 	"github.com/rs/zerolog/log"
 )
 
 // TMDBService handles TMDB API interactions with key rotation
 type TMDBService struct {
-	apiKeys    []string
+	keys       *keyPool
 	baseURL    string
 	imageBase  string
 	httpClient *http.Client
-	keyIndex   uint64 // 原子计数器，用于轮询
+
+	cache    *repository.Cache // 可为 nil
+	cacheTTL time.Duration
+	missTTL  time.Duration
+
+	configMu           sync.Mutex
+	imageConfig        *TMDBImageConfig // /configuration 图片尺寸缓存
+	imageConfigAt      time.Time
+	imageConfigRetryAt time.Time // 获取中或获取失败后，在此之前不再请求
 }
 
-// NewTMDBService creates a new TMDBService with multiple API keys
-func NewTMDBService(apiKeys []string, baseURL, imageBase string) *TMDBService {
+// NewTMDBService creates a new TMDBService with multiple API keys. Search,
+// find and image results are cached for cacheTTL, no-match results for
+// missTTL.
+func NewTMDBService(apiKeys []string, baseURL, imageBase string, cache *repository.Cache, cacheTTL, missTTL time.Duration) *TMDBService {
 	if len(apiKeys) > 0 {
 		log.Info().Int("count", len(apiKeys)).Msg("🔑 TMDB API Keys 已配置，启用轮询模式")
 	}
 	return &TMDBService{
-		apiKeys:   apiKeys,
+		keys:      newKeyPool(apiKeys),
 		baseURL:   baseURL,
 		imageBase: imageBase,
 		httpClient: &http.Client{
 			Timeout: 5 * time.Second,
 		},
-		keyIndex: 0,
+		cache:    cache,
+		cacheTTL: cacheTTL,
+		missTTL:  missTTL,
 	}
 }
 
-// getNextKey returns the next API key using round-robin
-func (s *TMDBService) getNextKey() string {
-	if len(s.apiKeys) == 0 {
-		return ""
-	}
-	idx := atomic.AddUint64(&s.keyIndex, 1) - 1
-	return s.apiKeys[idx%uint64(len(s.apiKeys))]
-}
-
 // TMDBSearchResult represents a TMDB search result
 type TMDBSearchResult struct {
 	ID            int     `json:"id"`
@@ -66,11 +72,78 @@ type TMDBSearchResponse struct {
 
 // SearchMovieBackdrop searches for a movie and returns its backdrop URL
 func (s *TMDBService) SearchMovieBackdrop(title string, year string) (string, error) {
-	apiKey := s.getNextKey()
-	if apiKey == "" {
-		return "", fmt.Errorf("TMDB API key not configured")
+	return s.SearchBackdrop(title, year, false)
+}
+
+// TMDBTitleMatch is the best search result for a title. Confidence is in
+// [0, 1], from the year and title agreement.
+type TMDBTitleMatch struct {
+	TMDBMatch
+	Title        string
+	BackdropPath string
+	Confidence   float64
+}
+
+// SearchBackdrop searches for a movie or, when isTV is set, a TV show and
+// returns its backdrop URL
+func (s *TMDBService) SearchBackdrop(title string, year string, isTV bool) (string, error) {
+	match, err := s.SearchTitle(title, year, isTV)
+	if err != nil || match == nil {
+		return "", err
+	}
+	return s.ImageURL(match.BackdropPath), nil
+}
+
+// LookupTitle fetches the title and backdrop of a known TMDB movie or TV show
+func (s *TMDBService) LookupTitle(match TMDBMatch) (*TMDBTitleMatch, error) {
+	params := url.Values{}
+	params.Set("language", "zh-CN")
+
+	var result struct {
+		Title        string `json:"title"`
+		Name         string `json:"name"`
+		BackdropPath string `json:"backdrop_path"`
+	}
+	if err := s.getJSON(fmt.Sprintf("/%s/%d", match.MediaType, match.ID), params, &result); err != nil {
+		return nil, err
 	}
 
+	title := result.Title
+	if match.MediaType == "tv" {
+		title = result.Name
+	}
+	return &TMDBTitleMatch{
+		TMDBMatch:    match,
+		Title:        title,
+		BackdropPath: result.BackdropPath,
+		Confidence:   1,
+	}, nil
+}
+
+// SearchTitle searches for a movie or, when isTV is set, a TV show and
+// returns the best match with a backdrop, or nil
+func (s *TMDBService) SearchTitle(title string, year string, isTV bool) (*TMDBTitleMatch, error) {
+	mediaType := "movie"
+	if isTV {
+		mediaType = "tv"
+	}
+
+	var match TMDBTitleMatch
+	found, err := s.cached(tmdbCacheKey("search", mediaType, title, year), &match, func() (bool, error) {
+		result, err := s.searchTitle(title, year, isTV)
+		if err != nil || result == nil {
+			return false, err
+		}
+		match = *result
+		return true, nil
+	})
+	if !found {
+		return nil, err
+	}
+	return &match, nil
+}
+
+func (s *TMDBService) searchTitle(title string, year string, isTV bool) (*TMDBTitleMatch, error) {
 	// Clean title - remove year in parentheses
 	cleanTitle := title
 	extractedYear := year
@@ -80,64 +153,155 @@ func (s *TMDBService) SearchMovieBackdrop(title string, year string) (string, er
 		cleanTitle = removeYearFromTitle(title)
 	}
 
-	// Build search URL
-	searchURL := fmt.Sprintf("%s/search/movie?query=%s&language=zh-CN",
-		s.baseURL, url.QueryEscape(cleanTitle))
-	if extractedYear != "" {
-		searchURL += fmt.Sprintf("&year=%s", extractedYear)
+	var result TMDBSearchResponse
+	if isTV {
+		// 豆瓣每季单独成条，TMDB 按剧集整体收录
+		show, season := splitSeasonTitle(cleanTitle)
+		results, err := s.searchTV(show, extractedYear, season)
+		if err != nil {
+			return nil, err
+		}
+		cleanTitle = show
+		result.Results = results
+		// 后续季的年份与首播年份不同，不参与打分
+		if season > 1 {
+			extractedYear = ""
+		}
+	} else {
+		params := url.Values{}
+		params.Set("query", cleanTitle)
+		params.Set("language", "zh-CN")
+		if extractedYear != "" {
+			params.Set("year", extractedYear)
+		}
+
+		if err := s.getJSON("/search/movie", params, &result); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(result.Results) == 0 {
+		log.Debug().Str("title", title).Msg("TMDB: no results found")
+		return nil, nil
+	}
+
+	// Find best match using scoring
+	bestMatch, confidence := s.findBestMatch(result.Results, cleanTitle, extractedYear)
+	if bestMatch == nil {
+		return nil, nil
+	}
+
+	log.Debug().
+		Str("title", title).
+		Str("matched", bestMatch.Title).
+		Bool("tv", isTV).
+		Float64("confidence", confidence).
+		Msg("TMDB: matched")
+
+	mediaType := "movie"
+	if isTV {
+		mediaType = "tv"
 	}
+	return &TMDBTitleMatch{
+		TMDBMatch:    TMDBMatch{ID: bestMatch.ID, MediaType: mediaType},
+		Title:        bestMatch.Title,
+		BackdropPath: bestMatch.BackdropPath,
+		Confidence:   confidence,
+	}, nil
+}
+
+// getJSON performs an authenticated GET against the TMDB API and decodes
+// the JSON response into dest. A request rejected for its key (401/429) is
+// retried once per remaining key.
+func (s *TMDBService) getJSON(path string, params url.Values, dest interface{}) error {
+	tried := make(map[*tmdbKey]bool)
+	for {
+		key, releaseAt := s.keys.acquire(tried)
+		if key == nil {
+			if !releaseAt.IsZero() {
+				return fmt.Errorf("all TMDB API keys quarantined until %s", releaseAt.Format(time.RFC3339))
+			}
+			if len(tried) > 0 {
+				return fmt.Errorf("no usable TMDB API key")
+			}
+			return fmt.Errorf("TMDB API key not configured")
+		}
+		tried[key] = true
 
-	// Make request
-	req, err := http.NewRequest("GET", searchURL, nil)
+		err := s.doJSON(key, path, params, dest)
+		var statusErr *tmdbStatusError
+		// 401/429 是 Key 本身的问题，换一个 Key 重试
+		if errors.As(err, &statusErr) && (statusErr.status == http.StatusUnauthorized || statusErr.status == http.StatusTooManyRequests) && len(tried) < s.keys.size() {
+			continue
+		}
+		return err
+	}
+}
+
+// tmdbStatusError is returned for non-200 TMDB responses
+type tmdbStatusError struct {
+	status int
+}
+
+func (e *tmdbStatusError) Error() string {
+	return fmt.Sprintf("TMDB returned status %d", e.status)
+}
+
+// doJSON performs a GET with one specific key and records the outcome
+func (s *TMDBService) doJSON(key *tmdbKey, path string, params url.Values, dest interface{}) error {
+	reqURL := s.baseURL + path
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest("GET", reqURL, nil)
 	if err != nil {
-		return "", err
+		return err
 	}
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.value))
 
 	resp, err := s.httpClient.Do(req)
+	s.keys.markResult(key, resp, err)
 	if err != nil {
-		return "", fmt.Errorf("TMDB search failed: %w", err)
+		return fmt.Errorf("TMDB request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("TMDB returned status %d", resp.StatusCode)
+		return &tmdbStatusError{status: resp.StatusCode}
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
-	}
-
-	var result TMDBSearchResponse
-	if err := json.Unmarshal(data, &result); err != nil {
-		return "", fmt.Errorf("failed to parse TMDB response: %w", err)
+		return err
 	}
 
-	if len(result.Results) == 0 {
-		log.Debug().Str("title", title).Msg("TMDB: no results found")
-		return "", nil
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to parse TMDB response: %w", err)
 	}
+	return nil
+}
 
-	// Find best match using scoring
-	bestMatch := s.findBestMatch(result.Results, cleanTitle, extractedYear)
-	if bestMatch == nil {
-		return "", nil
+// ImageURL returns the full image URL for a TMDB file path, or "" if empty
+func (s *TMDBService) ImageURL(path string) string {
+	if path == "" {
+		return ""
 	}
-
-	log.Debug().
-		Str("title", title).
-		Str("matched", bestMatch.Title).
-		Msg("TMDB: matched")
-
-	return fmt.Sprintf("%s%s", s.imageBase, bestMatch.BackdropPath), nil
+	return s.imageBase + path
 }
 
-// findBestMatch finds the best matching result using a scoring algorithm
-func (s *TMDBService) findBestMatch(results []TMDBSearchResult, searchTitle, year string) *TMDBSearchResult {
+// findBestMatch finds the best matching result using a scoring algorithm.
+// The confidence counts only year and title agreement, normalized to [0, 1].
+func (s *TMDBService) findBestMatch(results []TMDBSearchResult, searchTitle, year string) (*TMDBSearchResult, float64) {
 	var bestMatch *TMDBSearchResult
 	bestScore := 0.0
+	bestMatchScore := 0.0
+
+	maxMatchScore := 50.0
+	if year != "" {
+		maxMatchScore += 100
+	}
 
 	for i := range results {
 		result := &results[i]
@@ -145,17 +309,17 @@ func (s *TMDBService) findBestMatch(results []TMDBSearchResult, searchTitle, yea
 			continue
 		}
 
-		score := 0.0
+		matchScore := 0.0
 
 		// Year matching (most important)
 		if year != "" && len(result.ReleaseDate) >= 4 {
 			movieYear := result.ReleaseDate[:4]
 			if movieYear == year {
-				score += 100
+				matchScore += 100
 			} else {
 				yearDiff := abs(atoi(movieYear) - atoi(year))
 				if yearDiff <= 1 {
-					score += 50
+					matchScore += 50
 				}
 			}
 		}
@@ -168,32 +332,35 @@ func (s *TMDBService) findBestMatch(results []TMDBSearchResult, searchTitle, yea
 		searchLower := strings.ToLower(searchTitle)
 
 		if movieTitle == searchLower {
-			score += 50
+			matchScore += 50
 		} else if strings.Contains(movieTitle, searchLower) || strings.Contains(searchLower, movieTitle) {
-			score += 25
+			matchScore += 25
 		}
 
 		// Popularity and rating
+		score := matchScore
 		score += result.VoteAverage * 2
 		score += math.Log10(result.Popularity+1) * 5
 
 		if score > bestScore {
 			bestScore = score
+			bestMatchScore = matchScore
 			bestMatch = result
 		}
 	}
 
-	return bestMatch
+	confidence := math.Round(bestMatchScore/maxMatchScore*100) / 100
+	return bestMatch, confidence
 }
 
 // IsConfigured returns true if TMDB is configured
 func (s *TMDBService) IsConfigured() bool {
-	return len(s.apiKeys) > 0
+	return s.keys.size() > 0
 }
 
 // KeyCount returns the number of configured API keys
 func (s *TMDBService) KeyCount() int {
-	return len(s.apiKeys)
+	return s.keys.size()
 }
 
 // Helper functions
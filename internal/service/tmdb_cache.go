@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TMDBCacheKeyPrefix is the key family of cached TMDB API results. It is
+// separate from the response caches of the endpoints that use them, so
+// clearing e.g. the hero cache does not re-query TMDB.
+const TMDBCacheKeyPrefix = "tmdb:api:"
+
+// tmdbCacheEntry wraps a cached TMDB result. Found is false for a cached
+// no-match, which is kept for the shorter miss TTL.
+type tmdbCacheEntry struct {
+	Found bool            `json:"found"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// tmdbCacheKey joins key parts, e.g. tmdb:api:search:movie:肖申克的救赎:1994
+func tmdbCacheKey(parts ...string) string {
+	return TMDBCacheKeyPrefix + strings.Join(parts, ":")
+}
+
+// cached returns the result stored under key into dest, calling fetch on a
+// cache miss. fetch fills dest and reports whether anything was found.
+// No-match results and TMDB 404s are cached as misses; other errors are not
+// cached. Without a cache, fetch is called directly.
+func (s *TMDBService) cached(key string, dest interface{}, fetch func() (bool, error)) (bool, error) {
+	if s.cache == nil {
+		return fetch()
+	}
+	ctx := context.Background()
+
+	var entry tmdbCacheEntry
+	if err := s.cache.Get(ctx, key, &entry); err == nil {
+		if !entry.Found {
+			return false, nil
+		}
+		if err := json.Unmarshal(entry.Data, dest); err == nil {
+			return true, nil
+		}
+	}
+
+	found, err := fetch()
+	var statusErr *tmdbStatusError
+	if errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound {
+		found, err = false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		s.cache.Set(ctx, key, tmdbCacheEntry{Found: false}, s.missTTL)
+		return false, nil
+	}
+
+	data, err := json.Marshal(dest)
+	if err != nil {
+		return true, nil
+	}
+	if err := s.cache.Set(ctx, key, tmdbCacheEntry{Found: true, Data: data}, s.cacheTTL); err != nil {
+		log.Debug().Err(err).Str("key", key).Msg("Failed to cache TMDB result")
+	}
+	return true, nil
+}
+
+// ClearCache deletes all cached TMDB API results
+func (s *TMDBService) ClearCache(ctx context.Context) (int64, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	return s.cache.DeletePattern(ctx, TMDBCacheKeyPrefix+"*")
+}
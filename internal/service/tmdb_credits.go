@@ -0,0 +1,282 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"kerkerker-douban-service/internal/model"
+)
+
+const (
+	// profileImageSize is the TMDB size of profile photos
+	profileImageSize = "w185"
+	// maxCreditsCast caps the merged cast list
+	maxCreditsCast = 30
+)
+
+// creditsCrewJobs are the crew jobs kept in merged credits, in display order
+var creditsCrewJobs = []string{
+	"Director", "Screenplay", "Writer", "Novel", "Producer",
+	"Original Music Composer", "Director of Photography", "Editor",
+}
+
+// Credit match methods
+const (
+	CreditMatchName     = "name"     // 豆瓣与 TMDB 姓名一致
+	CreditMatchPosition = "position" // 姓名不一致时按顺位对应
+)
+
+// TMDBCastMember is a cast member of the TMDB /credits response
+type TMDBCastMember struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	OriginalName string `json:"original_name"`
+	Character    string `json:"character"`
+	Order        int    `json:"order"`
+	ProfilePath  string `json:"profile_path"`
+}
+
+// TMDBCrewMember is a crew member of the TMDB /credits response
+type TMDBCrewMember struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	OriginalName string `json:"original_name"`
+	Job          string `json:"job"`
+	Department   string `json:"department"`
+	ProfilePath  string `json:"profile_path"`
+}
+
+// TMDBCredits is the TMDB /{type}/{id}/credits response
+type TMDBCredits struct {
+	Cast []TMDBCastMember `json:"cast"`
+	Crew []TMDBCrewMember `json:"crew"`
+}
+
+// GetCredits returns the cast and crew of a TMDB title
+func (s *TMDBService) GetCredits(match TMDBMatch) (*TMDBCredits, error) {
+	params := url.Values{}
+	params.Set("language", "zh-CN")
+
+	var result TMDBCredits
+	key := tmdbCacheKey("credits", match.MediaType, strconv.Itoa(match.ID))
+	found, err := s.cached(key, &result, func() (bool, error) {
+		err := s.getJSON(fmt.Sprintf("/%s/%d/credits", match.MediaType, match.ID), params, &result)
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("TMDB %s %d not found", match.MediaType, match.ID)
+	}
+
+	sort.SliceStable(result.Cast, func(i, j int) bool {
+		return result.Cast[i].Order < result.Cast[j].Order
+	})
+	return &result, nil
+}
+
+// MergeCredits reconciles Douban directors and actors with TMDB credits.
+// People are matched by name first and by billing position otherwise.
+// Douban people keep their order; unmatched TMDB cast and key crew follow.
+func (s *TMDBService) MergeCredits(directors, actors []model.CelebrityRef, credits *TMDBCredits) *model.Credits {
+	castNames := make([]string, len(credits.Cast))
+	for i, m := range credits.Cast {
+		castNames[i] = m.Name + "\x00" + m.OriginalName
+	}
+	castMatches := reconcileNames(actors, castNames)
+
+	result := &model.Credits{Cast: []model.CreditPerson{}, Crew: []model.CreditPerson{}}
+	used := make(map[int]bool)
+	for i, actor := range actors {
+		person := model.CreditPerson{DoubanID: actor.ID, Name: actor.Name}
+		if m := castMatches[i]; m.index >= 0 {
+			tmdb := credits.Cast[m.index]
+			used[m.index] = true
+			person.TMDBID = tmdb.ID
+			person.TMDBName = tmdb.Name
+			person.Character = tmdb.Character
+			person.Profile = s.profileURL(tmdb.ProfilePath)
+			person.Match = m.method
+		}
+		result.Cast = append(result.Cast, person)
+	}
+	for i, tmdb := range credits.Cast {
+		if len(result.Cast) >= maxCreditsCast {
+			break
+		}
+		if used[i] {
+			continue
+		}
+		result.Cast = append(result.Cast, model.CreditPerson{
+			TMDBID:    tmdb.ID,
+			Name:      tmdb.Name,
+			TMDBName:  tmdb.Name,
+			Character: tmdb.Character,
+			Profile:   s.profileURL(tmdb.ProfilePath),
+		})
+	}
+	for i := range result.Cast {
+		result.Cast[i].Order = i
+		result.Cast[i].Department = "Acting"
+	}
+
+	// 导演与 TMDB 的 Director 对应，其余职位直接取 TMDB
+	var tmdbDirectors []TMDBCrewMember
+	crewByJob := make(map[string][]TMDBCrewMember)
+	seen := make(map[string]bool)
+	for _, m := range credits.Crew {
+		key := strconv.Itoa(m.ID) + ":" + m.Job
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if m.Job == "Director" {
+			tmdbDirectors = append(tmdbDirectors, m)
+		} else {
+			crewByJob[m.Job] = append(crewByJob[m.Job], m)
+		}
+	}
+
+	directorNames := make([]string, len(tmdbDirectors))
+	for i, m := range tmdbDirectors {
+		directorNames[i] = m.Name + "\x00" + m.OriginalName
+	}
+	directorMatches := reconcileNames(directors, directorNames)
+	used = make(map[int]bool)
+	for i, director := range directors {
+		person := model.CreditPerson{DoubanID: director.ID, Name: director.Name, Job: "Director", Department: "Directing"}
+		if m := directorMatches[i]; m.index >= 0 {
+			tmdb := tmdbDirectors[m.index]
+			used[m.index] = true
+			person.TMDBID = tmdb.ID
+			person.TMDBName = tmdb.Name
+			person.Profile = s.profileURL(tmdb.ProfilePath)
+			person.Match = m.method
+		}
+		result.Crew = append(result.Crew, person)
+	}
+	for i, tmdb := range tmdbDirectors {
+		if !used[i] {
+			result.Crew = append(result.Crew, s.crewPerson(tmdb))
+		}
+	}
+	for _, job := range creditsCrewJobs[1:] {
+		for _, tmdb := range crewByJob[job] {
+			result.Crew = append(result.Crew, s.crewPerson(tmdb))
+		}
+	}
+	for i := range result.Crew {
+		result.Crew[i].Order = i
+	}
+
+	return result
+}
+
+func (s *TMDBService) crewPerson(m TMDBCrewMember) model.CreditPerson {
+	return model.CreditPerson{
+		TMDBID:     m.ID,
+		Name:       m.Name,
+		TMDBName:   m.Name,
+		Job:        m.Job,
+		Department: m.Department,
+		Profile:    s.profileURL(m.ProfilePath),
+	}
+}
+
+func (s *TMDBService) profileURL(path string) string {
+	if path == "" {
+		return ""
+	}
+	return s.sizedImageBase() + profileImageSize + path
+}
+
+// creditMatch is the TMDB index matched to a Douban person, or -1
+type creditMatch struct {
+	index  int
+	method string
+}
+
+// reconcileNames matches Douban people to TMDB names ("name\x00original").
+// Names are compared first; people left over are paired by position when
+// the TMDB person at the same position is also unmatched and the two names
+// can't be compared, e.g. a Chinese name against a Latin one.
+func reconcileNames(people []model.CelebrityRef, tmdbNames []string) []creditMatch {
+	matches := make([]creditMatch, len(people))
+	taken := make(map[int]bool)
+
+	for i, p := range people {
+		matches[i] = creditMatch{index: -1}
+		name := normalizePersonName(p.Name)
+		if name == "" {
+			continue
+		}
+		for j, tmdbName := range tmdbNames {
+			if !taken[j] && personNameMatches(name, tmdbName) {
+				matches[i] = creditMatch{index: j, method: CreditMatchName}
+				taken[j] = true
+				break
+			}
+		}
+	}
+
+	for i := range people {
+		if matches[i].index < 0 && i < len(tmdbNames) && !taken[i] && !namesComparable(people[i].Name, tmdbNames[i]) {
+			matches[i] = creditMatch{index: i, method: CreditMatchPosition}
+			taken[i] = true
+		}
+	}
+	return matches
+}
+
+// personNameMatches compares a normalized Douban name with a TMDB name and
+// original name. Douban names may carry the Latin name after the Chinese
+// one, e.g. "蒂姆·罗宾斯 Tim Robbins".
+func personNameMatches(doubanName, tmdbNames string) bool {
+	for _, n := range strings.Split(tmdbNames, "\x00") {
+		n = normalizePersonName(n)
+		if n == "" {
+			continue
+		}
+		if n == doubanName || (len([]rune(n)) >= 4 && strings.Contains(doubanName, n)) {
+			return true
+		}
+	}
+	return false
+}
+
+// namesComparable reports whether a Douban name shares a script (Han or
+// Latin) with a TMDB name, in which case differing names are different people
+func namesComparable(doubanName, tmdbNames string) bool {
+	han, latin := nameScripts(doubanName)
+	tmdbHan, tmdbLatin := nameScripts(tmdbNames)
+	return (han && tmdbHan) || (latin && tmdbLatin)
+}
+
+func nameScripts(name string) (han, latin bool) {
+	for _, r := range name {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han = true
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		}
+	}
+	return han, latin
+}
+
+// normalizePersonName lower-cases a name and drops spaces and separators
+// such as "·" and "-"
+func normalizePersonName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
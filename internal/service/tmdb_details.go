@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+)
+
+// detailLanguages are the languages tried for overviews and taglines, in order
+var detailLanguages = []string{"zh-CN", "en-US"}
+
+// TMDBDetails holds the localized fields of the TMDB /{type}/{id} response
+type TMDBDetails struct {
+	Overview string `json:"overview"`
+	Tagline  string `json:"tagline"`
+	Genres   []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"genres"`
+}
+
+// GetDetails returns the localized details of a TMDB title
+func (s *TMDBService) GetDetails(match TMDBMatch, language string) (*TMDBDetails, error) {
+	params := url.Values{}
+	params.Set("language", language)
+
+	var result TMDBDetails
+	key := tmdbCacheKey("details", match.MediaType, strconv.Itoa(match.ID), language)
+	found, err := s.cached(key, &result, func() (bool, error) {
+		err := s.getJSON(fmt.Sprintf("/%s/%d", match.MediaType, match.ID), params, &result)
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("TMDB %s %d not found", match.MediaType, match.ID)
+	}
+	return &result, nil
+}
+
+// GetLocalizedText returns the overview, tagline and genres of a TMDB
+// title. The overview and tagline fall back to English when TMDB has no
+// Chinese translation; genre names are always localized by TMDB.
+func (s *TMDBService) GetLocalizedText(match TMDBMatch) (*model.LocalizedText, error) {
+	text := &model.LocalizedText{}
+	for i, language := range detailLanguages {
+		details, err := s.GetDetails(match, language)
+		if err != nil {
+			// 中文失败直接返回，英文仅作补充
+			if i == 0 {
+				return nil, err
+			}
+			break
+		}
+
+		if text.Overview == "" {
+			text.Overview = details.Overview
+		}
+		if text.Tagline == "" {
+			text.Tagline = details.Tagline
+		}
+		if i == 0 {
+			for _, g := range details.Genres {
+				text.Genres = append(text.Genres, g.Name)
+			}
+		}
+		if text.Overview != "" && text.Tagline != "" {
+			break
+		}
+	}
+	return text, nil
+}
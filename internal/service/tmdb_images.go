@@ -0,0 +1,205 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kerkerker-douban-service/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// imageConfigTTL is how long the TMDB /configuration image sizes are reused
+	imageConfigTTL = 24 * time.Hour
+	// imageConfigRetry is how long to wait before fetching /configuration
+	// again after a failure
+	imageConfigRetry = 5 * time.Minute
+)
+
+// imageLanguages is the language preference for artwork; "" is textless
+var imageLanguages = []string{"zh", "", "en"}
+
+// TMDBImageConfig lists the image sizes TMDB serves per image type
+type TMDBImageConfig struct {
+	BackdropSizes []string `json:"backdrop_sizes"`
+	PosterSizes   []string `json:"poster_sizes"`
+	LogoSizes     []string `json:"logo_sizes"`
+}
+
+// defaultImageConfig is used until /configuration has been fetched
+var defaultImageConfig = TMDBImageConfig{
+	BackdropSizes: []string{"w300", "w780", "w1280", "original"},
+	PosterSizes:   []string{"w92", "w154", "w185", "w342", "w500", "w780", "original"},
+	LogoSizes:     []string{"w45", "w92", "w154", "w185", "w300", "w500", "original"},
+}
+
+// TMDBImage is an image of the TMDB /images response
+type TMDBImage struct {
+	FilePath    string  `json:"file_path"`
+	Language    string  `json:"iso_639_1"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	AspectRatio float64 `json:"aspect_ratio"`
+	VoteAverage float64 `json:"vote_average"`
+}
+
+// TMDBImagesResponse is the TMDB /{type}/{id}/images response
+type TMDBImagesResponse struct {
+	Backdrops []TMDBImage `json:"backdrops"`
+	Posters   []TMDBImage `json:"posters"`
+	Logos     []TMDBImage `json:"logos"`
+}
+
+// ImageConfig returns the TMDB image sizes, refreshing them from
+// /configuration once a day. Falls back to the last or default sizes on
+// failure, and waits imageConfigRetry before fetching again. The lock is
+// not held during the fetch; concurrent callers get the current sizes.
+func (s *TMDBService) ImageConfig() TMDBImageConfig {
+	s.configMu.Lock()
+	current := defaultImageConfig
+	if s.imageConfig != nil {
+		current = *s.imageConfig
+	}
+	fresh := s.imageConfig != nil && time.Since(s.imageConfigAt) < imageConfigTTL
+	if fresh || time.Now().Before(s.imageConfigRetryAt) {
+		s.configMu.Unlock()
+		return current
+	}
+	// 获取期间其他调用方按重试间隔处理，直接使用当前尺寸
+	s.imageConfigRetryAt = time.Now().Add(imageConfigRetry)
+	s.configMu.Unlock()
+
+	var result struct {
+		Images TMDBImageConfig `json:"images"`
+	}
+	if err := s.getJSON("/configuration", nil, &result); err != nil || len(result.Images.BackdropSizes) == 0 {
+		log.Warn().Err(err).Msg("Failed to fetch TMDB configuration, using default image sizes")
+		return current
+	}
+
+	s.configMu.Lock()
+	s.imageConfig = &result.Images
+	s.imageConfigAt = time.Now()
+	s.imageConfigRetryAt = time.Time{}
+	s.configMu.Unlock()
+	return result.Images
+}
+
+// GetImages returns the backdrops, posters and logos of a TMDB title in
+// every configured size, ordered by language preference (zh, textless, en)
+// and then by rating
+func (s *TMDBService) GetImages(match TMDBMatch) (*model.Artwork, error) {
+	params := url.Values{}
+	params.Set("include_image_language", "zh,null,en")
+
+	// 缓存原始结果，尺寸列表变化时无需重新请求
+	var result TMDBImagesResponse
+	key := tmdbCacheKey("images", match.MediaType, strconv.Itoa(match.ID))
+	found, err := s.cached(key, &result, func() (bool, error) {
+		err := s.getJSON(fmt.Sprintf("/%s/%d/images", match.MediaType, match.ID), params, &result)
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("TMDB %s %d not found", match.MediaType, match.ID)
+	}
+
+	config := s.ImageConfig()
+	return &model.Artwork{
+		TMDBID:    match.ID,
+		MediaType: match.MediaType,
+		Backdrops: s.artworkImages(result.Backdrops, config.BackdropSizes),
+		Posters:   s.artworkImages(result.Posters, config.PosterSizes),
+		Logos:     s.artworkImages(result.Logos, config.LogoSizes),
+	}, nil
+}
+
+// artworkImages sorts images by language preference and rating and expands
+// each into its size variants
+func (s *TMDBService) artworkImages(images []TMDBImage, sizes []string) []model.ArtworkImage {
+	sorted := make([]TMDBImage, 0, len(images))
+	for _, img := range images {
+		if img.FilePath != "" && languageRank(img.Language) < len(imageLanguages) {
+			sorted = append(sorted, img)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, rj := languageRank(sorted[i].Language), languageRank(sorted[j].Language)
+		if ri != rj {
+			return ri < rj
+		}
+		return sorted[i].VoteAverage > sorted[j].VoteAverage
+	})
+
+	result := make([]model.ArtworkImage, len(sorted))
+	for i, img := range sorted {
+		result[i] = model.ArtworkImage{
+			Language:    img.Language,
+			Width:       img.Width,
+			Height:      img.Height,
+			AspectRatio: img.AspectRatio,
+			VoteAverage: img.VoteAverage,
+			Variants:    s.ImageVariants(img.FilePath, sizes, img.Width, img.Height),
+		}
+	}
+	return result
+}
+
+// ImageVariants builds the URL of an image file in each size. Sizes larger
+// than the original are skipped.
+func (s *TMDBService) ImageVariants(path string, sizes []string, width, height int) []model.ImageVariant {
+	base := s.sizedImageBase()
+
+	var variants []model.ImageVariant
+	for _, size := range sizes {
+		if size == "" {
+			continue
+		}
+		variant := model.ImageVariant{Size: size, URL: base + size + path}
+		n, _ := strconv.Atoi(size[1:])
+		switch {
+		case size == "original":
+			variant.Width = width
+		case strings.HasPrefix(size, "w"):
+			if width > 0 && n > width {
+				continue
+			}
+			variant.Width = n
+		case strings.HasPrefix(size, "h"):
+			if height > 0 && n > height {
+				continue
+			}
+			if height > 0 {
+				variant.Width = n * width / height
+			}
+		}
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+// sizedImageBase derives the size-less image base from TMDB_IMAGE_BASE,
+// e.g. https://image.tmdb.org/t/p/original -> https://image.tmdb.org/t/p/
+func (s *TMDBService) sizedImageBase() string {
+	base := strings.TrimSuffix(s.imageBase, "/")
+	if i := strings.LastIndex(base, "/"); i >= 0 {
+		return base[:i+1]
+	}
+	return base + "/"
+}
+
+func languageRank(lang string) int {
+	for i, l := range imageLanguages {
+		if l == lang {
+			return i
+		}
+	}
+	return len(imageLanguages)
+}
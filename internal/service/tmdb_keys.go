@@ -0,0 +1,349 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// keyRevokedQuarantine is doubled on each repeated 401, up to keyMaxQuarantine
+	keyRevokedQuarantine = time.Hour
+	keyMaxQuarantine     = 24 * time.Hour
+	// keyRateLimitQuarantine is used for 429s without a Retry-After header
+	keyRateLimitQuarantine = 10 * time.Second
+	// keyErrorQuarantine applies after maxKeyErrors consecutive network or 5xx errors
+	keyErrorQuarantine = time.Minute
+	maxKeyErrors       = 3
+)
+
+// ErrKeyNotFound is returned when no configured key has the given ID
+var ErrKeyNotFound = fmt.Errorf("TMDB key not found")
+
+// tmdbKey is a TMDB API key with its health counters
+type tmdbKey struct {
+	id    string
+	value string
+
+	requests          int64
+	failures          int64
+	consecutiveErrors int
+	revocations       int
+	lastStatus        int
+	lastError         string
+	lastUsed          time.Time
+	quarantinedUntil  time.Time
+	quarantineReason  string
+}
+
+// TMDBKeyStatus is a snapshot of a key's health. The key value is masked.
+type TMDBKeyStatus struct {
+	ID               string     `json:"id"`
+	Key              string     `json:"key"`
+	Requests         int64      `json:"requests"`
+	Failures         int64      `json:"failures"`
+	LastStatus       int        `json:"last_status,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	LastUsed         *time.Time `json:"last_used,omitempty"`
+	Quarantined      bool       `json:"quarantined"`
+	QuarantinedUntil *time.Time `json:"quarantined_until,omitempty"`
+	QuarantineReason string     `json:"quarantine_reason,omitempty"`
+}
+
+// keyPool hands out TMDB keys round-robin, skipping quarantined ones.
+// A key whose quarantine has expired is simply handed out again, so its
+// next request acts as the re-probe.
+type keyPool struct {
+	mu   sync.Mutex
+	keys []*tmdbKey
+	next int
+}
+
+func newKeyPool(values []string) *keyPool {
+	p := &keyPool{}
+	for _, v := range values {
+		p.add(v)
+	}
+	return p
+}
+
+// keyID is a short fingerprint that identifies a key without revealing it
+func keyID(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:4])
+}
+
+// maskKey keeps only the first and last 4 characters of a key
+func maskKey(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + "…" + value[len(value)-4:]
+}
+
+// add appends a key; returns false when it is empty or already present
+func (p *keyPool) add(value string) (*tmdbKey, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := keyID(value)
+	for _, k := range p.keys {
+		if k.id == id {
+			return k, false
+		}
+	}
+	k := &tmdbKey{id: id, value: value}
+	p.keys = append(p.keys, k)
+	return k, true
+}
+
+// remove deletes a key by ID
+func (p *keyPool) remove(id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, k := range p.keys {
+		if k.id == id {
+			p.keys = append(p.keys[:i], p.keys[i+1:]...)
+			if p.next > i {
+				p.next--
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// get returns a key by ID
+func (p *keyPool) get(id string) *tmdbKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, k := range p.keys {
+		if k.id == id {
+			return k
+		}
+	}
+	return nil
+}
+
+// acquire returns the next healthy key not in tried. Quarantined keys are
+// never used; when no key is available it returns nil and the time the
+// first quarantined key is released (zero if none is quarantined).
+func (p *keyPool) acquire(tried map[*tmdbKey]bool) (*tmdbKey, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var releaseAt time.Time
+	for i := 0; i < len(p.keys); i++ {
+		k := p.keys[(p.next+i)%len(p.keys)]
+		if tried[k] {
+			continue
+		}
+		if now.Before(k.quarantinedUntil) {
+			if releaseAt.IsZero() || k.quarantinedUntil.Before(releaseAt) {
+				releaseAt = k.quarantinedUntil
+			}
+			continue
+		}
+		p.next = (p.next + i + 1) % len(p.keys)
+		return k, time.Time{}
+	}
+	return nil, releaseAt
+}
+
+// markResult updates a key's health from a response, or from err when the
+// request failed before a response arrived
+func (p *keyPool) markResult(k *tmdbKey, resp *http.Response, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	k.requests++
+	k.lastUsed = now
+
+	var until time.Time
+	var reason string
+
+	switch {
+	case err != nil:
+		k.lastStatus = 0
+		k.lastError = err.Error()
+		k.failures++
+		k.consecutiveErrors++
+		if k.consecutiveErrors >= maxKeyErrors {
+			until, reason = now.Add(keyErrorQuarantine), "consecutive errors"
+		}
+
+	case resp.StatusCode == http.StatusUnauthorized:
+		k.lastStatus = resp.StatusCode
+		k.lastError = "unauthorized"
+		k.failures++
+		k.revocations++
+		d := keyRevokedQuarantine << (k.revocations - 1)
+		if d <= 0 || d > keyMaxQuarantine {
+			d = keyMaxQuarantine
+		}
+		until, reason = now.Add(d), "unauthorized"
+
+	case resp.StatusCode == http.StatusTooManyRequests:
+		k.lastStatus = resp.StatusCode
+		k.lastError = "rate limited"
+		k.failures++
+		until, reason = now.Add(retryAfter(resp.Header, now)), "rate limited"
+
+	case resp.StatusCode >= 500:
+		k.lastStatus = resp.StatusCode
+		k.lastError = resp.Status
+		k.failures++
+		k.consecutiveErrors++
+		if k.consecutiveErrors >= maxKeyErrors {
+			until, reason = now.Add(keyErrorQuarantine), "consecutive errors"
+		}
+
+	default:
+		// 4xx 其余状态码（如 404）属于请求本身的问题，不影响 Key 健康
+		k.lastStatus = resp.StatusCode
+		k.lastError = ""
+		k.consecutiveErrors = 0
+		k.revocations = 0
+		k.quarantinedUntil = time.Time{}
+		k.quarantineReason = ""
+
+		// 额度耗尽时提前暂停到重置时间
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset := rateLimitReset(resp.Header, now); reset.After(now) {
+				until, reason = reset, "rate limit exhausted"
+			}
+		}
+	}
+
+	if !until.IsZero() && until.After(k.quarantinedUntil) {
+		k.quarantinedUntil = until
+		k.quarantineReason = reason
+		log.Warn().Str("key", k.id).Str("reason", reason).Time("until", until).Msg("🔑 TMDB API Key 已暂停使用")
+	}
+}
+
+// status returns a snapshot of all keys
+func (p *keyPool) status() []TMDBKeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	result := make([]TMDBKeyStatus, len(p.keys))
+	for i, k := range p.keys {
+		result[i] = TMDBKeyStatus{
+			ID:         k.id,
+			Key:        maskKey(k.value),
+			Requests:   k.requests,
+			Failures:   k.failures,
+			LastStatus: k.lastStatus,
+			LastError:  k.lastError,
+		}
+		if !k.lastUsed.IsZero() {
+			lastUsed := k.lastUsed
+			result[i].LastUsed = &lastUsed
+		}
+		if now.Before(k.quarantinedUntil) {
+			until := k.quarantinedUntil
+			result[i].Quarantined = true
+			result[i].QuarantinedUntil = &until
+			result[i].QuarantineReason = k.quarantineReason
+		}
+	}
+	return result
+}
+
+func (p *keyPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.keys)
+}
+
+// retryAfter reads Retry-After as seconds or an HTTP date
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	v := h.Get("Retry-After")
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return keyRateLimitQuarantine
+}
+
+// rateLimitReset reads X-RateLimit-Reset as a Unix timestamp
+func rateLimitReset(h http.Header, now time.Time) time.Time {
+	secs, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	reset := time.Unix(secs, 0)
+	// 防止异常的重置时间导致长期停用
+	if reset.Sub(now) > keyErrorQuarantine {
+		reset = now.Add(keyErrorQuarantine)
+	}
+	return reset
+}
+
+// KeyStatus returns the health of all configured keys with masked values
+func (s *TMDBService) KeyStatus() []TMDBKeyStatus {
+	return s.keys.status()
+}
+
+// AddKey adds an API key at runtime and returns its status. Keys added at
+// runtime are not persisted.
+func (s *TMDBService) AddKey(value string) (*TMDBKeyStatus, bool) {
+	k, added := s.keys.add(value)
+	if k == nil {
+		return nil, false
+	}
+	if added {
+		log.Info().Str("key", k.id).Msg("🔑 已添加 TMDB API Key")
+	}
+	return s.keyStatus(k.id), added
+}
+
+// RemoveKey removes an API key by ID
+func (s *TMDBService) RemoveKey(id string) bool {
+	removed := s.keys.remove(id)
+	if removed {
+		log.Info().Str("key", id).Msg("🔑 已移除 TMDB API Key")
+	}
+	return removed
+}
+
+// TestKey probes TMDB with one specific key and records the outcome
+func (s *TMDBService) TestKey(id string) (*TMDBKeyStatus, error) {
+	k := s.keys.get(id)
+	if k == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	var result struct{}
+	err := s.doJSON(k, "/configuration", nil, &result)
+	return s.keyStatus(id), err
+}
+
+func (s *TMDBService) keyStatus(id string) *TMDBKeyStatus {
+	for _, st := range s.keys.status() {
+		if st.ID == id {
+			return &st
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+
+	"kerkerker-douban-service/internal/model"
+	"kerkerker-douban-service/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TMDBMatcher resolves Douban subjects to TMDB titles. Static overrides
+// come first, then stored mappings, then the IMDb ID via /find, and title
+// and year search only as a fallback. New matches are stored with their
+// method and confidence.
+type TMDBMatcher struct {
+	tmdb      *TMDBService
+	mappings  *repository.MappingStore
+	overrides *StaticProvider
+}
+
+// NewTMDBMatcher creates a new TMDBMatcher. The tmdb_id and imdb_id of
+// overrides take precedence over automatic matching.
+func NewTMDBMatcher(tmdb *TMDBService, mappings *repository.MappingStore, overrides *StaticProvider) *TMDBMatcher {
+	return &TMDBMatcher{
+		tmdb:      tmdb,
+		mappings:  mappings,
+		overrides: overrides,
+	}
+}
+
+// MatchQuery describes the Douban subject to match
+type MatchQuery struct {
+	DoubanID string
+	Title    string // 为空时不做标题搜索
+	Year     string
+	IsTV     bool
+	IMDbID   func() string // 按需获取 IMDb 编号（通常需要请求条目页面），可为 nil
+}
+
+// Match returns the TMDB mapping of a subject, or nil when it can't be
+// matched. A stored title match is upgraded once an IMDb match is found.
+func (m *TMDBMatcher) Match(ctx context.Context, q MatchQuery) (*model.TMDBMapping, error) {
+	// 手动覆盖的 TMDB 编号直接使用，IMDb 编号替代从条目页面读取
+	if ids, _ := m.overrides.ExternalIDs(ctx, MetadataSubject{DoubanID: q.DoubanID}); ids != nil {
+		if ids.TMDBID > 0 && ids.MediaType != "" {
+			return &model.TMDBMapping{
+				DoubanID:   q.DoubanID,
+				TMDBID:     ids.TMDBID,
+				MediaType:  ids.MediaType,
+				Confidence: 1,
+				Method:     model.MatchMethodManual,
+				IMDbID:     ids.IMDbID,
+				Title:      q.Title,
+			}, nil
+		}
+		if ids.IMDbID != "" {
+			q.IMDbID = func() string { return ids.IMDbID }
+		}
+	}
+
+	stored, err := m.mappings.Get(ctx, q.DoubanID)
+	if err != nil {
+		log.Warn().Err(err).Str("id", q.DoubanID).Msg("Failed to read TMDB mapping")
+	}
+	if stored != nil && stored.Method != model.MatchMethodTitle {
+		return stored, nil
+	}
+
+	if imdbID := m.imdbID(q); imdbID != "" {
+		match, err := m.tmdb.FindByIMDb(imdbID)
+		if err != nil {
+			log.Warn().Err(err).Str("imdb", imdbID).Msg("TMDB find failed")
+		} else if match != nil {
+			mapping := newMapping(q, match, model.MatchMethodIMDb)
+			mapping.IMDbID = imdbID
+			m.store(ctx, mapping)
+			return mapping, nil
+		}
+	}
+
+	if stored != nil {
+		return stored, nil
+	}
+	if q.Title == "" {
+		return nil, nil
+	}
+
+	match, err := m.tmdb.SearchTitle(q.Title, q.Year, q.IsTV)
+	if err != nil || match == nil {
+		return nil, err
+	}
+
+	mapping := newMapping(q, match, model.MatchMethodTitle)
+	m.store(ctx, mapping)
+	return mapping, nil
+}
+
+func (m *TMDBMatcher) imdbID(q MatchQuery) string {
+	if q.IMDbID == nil {
+		return ""
+	}
+	return q.IMDbID()
+}
+
+func (m *TMDBMatcher) store(ctx context.Context, mapping *model.TMDBMapping) {
+	if err := m.mappings.Set(ctx, mapping); err != nil {
+		log.Warn().Err(err).Str("id", mapping.DoubanID).Msg("Failed to store TMDB mapping")
+	}
+}
+
+func newMapping(q MatchQuery, match *TMDBTitleMatch, method string) *model.TMDBMapping {
+	return &model.TMDBMapping{
+		DoubanID:     q.DoubanID,
+		TMDBID:       match.ID,
+		MediaType:    match.MediaType,
+		Confidence:   match.Confidence,
+		Method:       method,
+		Title:        q.Title,
+		MatchedTitle: match.Title,
+	}
+}
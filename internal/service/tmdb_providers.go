@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kerkerker-douban-service/internal/model"
+)
+
+// providerLogoSize is the TMDB size of provider logos
+const providerLogoSize = "w92"
+
+// reWatchRegion matches an ISO 3166-1 country code
+var reWatchRegion = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// TMDBWatchProvider is a provider of the TMDB /watch/providers response
+type TMDBWatchProvider struct {
+	ID              int    `json:"provider_id"`
+	Name            string `json:"provider_name"`
+	LogoPath        string `json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+}
+
+// TMDBRegionProviders are the providers of one region. Link is the TMDB
+// watch page of the title, which links out to each provider.
+type TMDBRegionProviders struct {
+	Link     string              `json:"link"`
+	Flatrate []TMDBWatchProvider `json:"flatrate"`
+	Free     []TMDBWatchProvider `json:"free"`
+	Ads      []TMDBWatchProvider `json:"ads"`
+	Rent     []TMDBWatchProvider `json:"rent"`
+	Buy      []TMDBWatchProvider `json:"buy"`
+}
+
+// NormalizeWatchRegion upper-cases a region code and reports whether it is
+// a valid ISO 3166-1 code
+func NormalizeWatchRegion(region string) (string, bool) {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	return region, reWatchRegion.MatchString(region)
+}
+
+// GetWatchProviders returns where a TMDB title can be watched in a region.
+// Subscription, free and ad-supported offers are merged into Streaming.
+// Returns nil when TMDB lists no providers for the region.
+func (s *TMDBService) GetWatchProviders(match TMDBMatch, region string) (*model.WatchProviders, error) {
+	// 按条目缓存全部地区，切换地区无需重新请求
+	var result struct {
+		Results map[string]TMDBRegionProviders `json:"results"`
+	}
+	key := tmdbCacheKey("providers", match.MediaType, strconv.Itoa(match.ID))
+	found, err := s.cached(key, &result, func() (bool, error) {
+		err := s.getJSON(fmt.Sprintf("/%s/%d/watch/providers", match.MediaType, match.ID), nil, &result)
+		return err == nil && len(result.Results) > 0, err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+
+	providers, ok := result.Results[region]
+	if !ok {
+		return nil, nil
+	}
+
+	streaming := append(append(append([]TMDBWatchProvider{}, providers.Flatrate...), providers.Free...), providers.Ads...)
+	return &model.WatchProviders{
+		Region:    region,
+		Link:      providers.Link,
+		Streaming: s.watchProviders(streaming),
+		Rent:      s.watchProviders(providers.Rent),
+		Buy:       s.watchProviders(providers.Buy),
+	}, nil
+}
+
+// watchProviders dedupes providers and orders them by TMDB display priority
+func (s *TMDBService) watchProviders(providers []TMDBWatchProvider) []model.WatchProvider {
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].DisplayPriority < providers[j].DisplayPriority
+	})
+
+	seen := make(map[int]bool)
+	result := []model.WatchProvider{}
+	for _, p := range providers {
+		if seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		provider := model.WatchProvider{ID: p.ID, Name: p.Name}
+		if p.LogoPath != "" {
+			provider.Logo = s.sizedImageBase() + providerLogoSize + p.LogoPath
+		}
+		result = append(result, provider)
+	}
+	return result
+}
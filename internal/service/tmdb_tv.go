@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// reSeasonTitle matches Douban's season suffix, e.g. "隐秘的角落 第二季"
+var reSeasonTitle = regexp.MustCompile(`^(.+?)\s*第([一二三四五六七八九十\d]+)季$`)
+
+// TMDBFindResponse is the TMDB /find response for an external ID
+type TMDBFindResponse struct {
+	MovieResults []struct {
+		ID           int    `json:"id"`
+		Title        string `json:"title"`
+		BackdropPath string `json:"backdrop_path"`
+	} `json:"movie_results"`
+	TVResults []struct {
+		ID           int    `json:"id"`
+		Name         string `json:"name"`
+		BackdropPath string `json:"backdrop_path"`
+	} `json:"tv_results"`
+}
+
+// TMDBMatch identifies a TMDB title
+type TMDBMatch struct {
+	ID        int    `json:"id"`
+	MediaType string `json:"media_type"` // movie / tv
+}
+
+// TMDBEpisode is an episode of a TMDB season
+type TMDBEpisode struct {
+	EpisodeNumber int    `json:"episode_number"`
+	Name          string `json:"name"`
+	Overview      string `json:"overview"`
+	AirDate       string `json:"air_date"`
+	StillPath     string `json:"still_path"`
+	Runtime       int    `json:"runtime"`
+}
+
+// TMDBSeasonResponse is the TMDB /tv/{id}/season/{n} response
+type TMDBSeasonResponse struct {
+	SeasonNumber int           `json:"season_number"`
+	Episodes     []TMDBEpisode `json:"episodes"`
+}
+
+// TMDBTVSearchResult is a TMDB /search/tv result
+type TMDBTVSearchResult struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	OriginalName string  `json:"original_name"`
+	BackdropPath string  `json:"backdrop_path"`
+	FirstAirDate string  `json:"first_air_date"`
+	VoteAverage  float64 `json:"vote_average"`
+	Popularity   float64 `json:"popularity"`
+}
+
+// searchTV searches TMDB TV shows by name. The first air year only narrows
+// the search for a first season.
+func (s *TMDBService) searchTV(name, year string, season int) ([]TMDBSearchResult, error) {
+	params := url.Values{}
+	params.Set("query", name)
+	params.Set("language", "zh-CN")
+	if year != "" && season <= 1 {
+		params.Set("first_air_date_year", year)
+	}
+
+	var result struct {
+		Results []TMDBTVSearchResult `json:"results"`
+	}
+	if err := s.getJSON("/search/tv", params, &result); err != nil {
+		return nil, err
+	}
+
+	// 转换为电影搜索结果的字段，复用 findBestMatch 的打分
+	results := make([]TMDBSearchResult, len(result.Results))
+	for i, r := range result.Results {
+		results[i] = TMDBSearchResult{
+			ID:            r.ID,
+			Title:         r.Name,
+			OriginalTitle: r.OriginalName,
+			BackdropPath:  r.BackdropPath,
+			ReleaseDate:   r.FirstAirDate,
+			VoteAverage:   r.VoteAverage,
+			Popularity:    r.Popularity,
+		}
+	}
+	return results, nil
+}
+
+// splitSeasonTitle splits "名称 第二季" into the show name and season
+// number. Titles without a season suffix are season 1.
+func splitSeasonTitle(title string) (string, int) {
+	m := reSeasonTitle.FindStringSubmatch(strings.TrimSpace(title))
+	if m == nil {
+		return strings.TrimSpace(title), 1
+	}
+	season := parseChineseNumber(m[2])
+	if season == 0 {
+		return strings.TrimSpace(title), 1
+	}
+	return m[1], season
+}
+
+// parseChineseNumber parses Arabic digits or Chinese numerals up to 99,
+// e.g. "二" -> 2, "十二" -> 12, "二十" -> 20
+func parseChineseNumber(s string) int {
+	if isDigits(s) {
+		return atoi(s)
+	}
+
+	digits := map[rune]int{'一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	tens, n := 0, 0
+	for _, r := range s {
+		if r == '十' {
+			tens = max(n, 1)
+			n = 0
+			continue
+		}
+		n = digits[r]
+	}
+	return tens*10 + n
+}
+
+// FindByIMDb resolves an IMDb ID to a TMDB movie or TV show. Returns nil
+// when TMDB has no title for it.
+func (s *TMDBService) FindByIMDb(imdbID string) (*TMDBTitleMatch, error) {
+	var match TMDBTitleMatch
+	found, err := s.cached(tmdbCacheKey("find", imdbID), &match, func() (bool, error) {
+		result, err := s.findByIMDb(imdbID)
+		if err != nil || result == nil {
+			return false, err
+		}
+		match = *result
+		return true, nil
+	})
+	if !found {
+		return nil, err
+	}
+	return &match, nil
+}
+
+func (s *TMDBService) findByIMDb(imdbID string) (*TMDBTitleMatch, error) {
+	params := url.Values{}
+	params.Set("external_source", "imdb_id")
+	params.Set("language", "zh-CN")
+
+	var result TMDBFindResponse
+	if err := s.getJSON("/find/"+url.PathEscape(imdbID), params, &result); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(result.MovieResults) > 0:
+		r := result.MovieResults[0]
+		return &TMDBTitleMatch{
+			TMDBMatch:    TMDBMatch{ID: r.ID, MediaType: "movie"},
+			Title:        r.Title,
+			BackdropPath: r.BackdropPath,
+			Confidence:   1,
+		}, nil
+	case len(result.TVResults) > 0:
+		r := result.TVResults[0]
+		return &TMDBTitleMatch{
+			TMDBMatch:    TMDBMatch{ID: r.ID, MediaType: "tv"},
+			Title:        r.Name,
+			BackdropPath: r.BackdropPath,
+			Confidence:   1,
+		}, nil
+	}
+	return nil, nil
+}
+
+// GetSeasonEpisodes returns the episodes of one season of a TMDB TV show
+func (s *TMDBService) GetSeasonEpisodes(tvID, season int) ([]TMDBEpisode, error) {
+	params := url.Values{}
+	params.Set("language", "zh-CN")
+
+	var result TMDBSeasonResponse
+	if err := s.getJSON(fmt.Sprintf("/tv/%d/season/%d", tvID, season), params, &result); err != nil {
+		return nil, err
+	}
+	return result.Episodes, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"kerkerker-douban-service/internal/model"
+)
+
+// TMDBVideo is an entry of the TMDB /videos response
+type TMDBVideo struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Site        string `json:"site"`
+	Type        string `json:"type"`
+	Language    string `json:"iso_639_1"`
+	Official    bool   `json:"official"`
+	PublishedAt string `json:"published_at"`
+}
+
+// TMDBVideosResponse is the TMDB /{movie|tv}/{id}/videos response
+type TMDBVideosResponse struct {
+	Results []TMDBVideo `json:"results"`
+}
+
+// tmdbVideoTypes maps TMDB video types to ours
+var tmdbVideoTypes = map[string]string{
+	"Trailer":           VideoTypeTrailer,
+	"Teaser":            VideoTypeTrailer,
+	"Clip":              VideoTypeClip,
+	"Featurette":        VideoTypeFeaturette,
+	"Behind the Scenes": VideoTypeFeaturette,
+}
+
+// GetVideos returns the Chinese, English and language-neutral videos of a
+// TMDB title
+func (s *TMDBService) GetVideos(match TMDBMatch) ([]model.Video, error) {
+	params := url.Values{}
+	params.Set("language", "zh-CN")
+	params.Set("include_video_language", "zh,en,null")
+
+	// 未找到的条目按空列表处理
+	var result TMDBVideosResponse
+	key := tmdbCacheKey("videos", match.MediaType, strconv.Itoa(match.ID))
+	if _, err := s.cached(key, &result, func() (bool, error) {
+		err := s.getJSON(fmt.Sprintf("/%s/%d/videos", match.MediaType, match.ID), params, &result)
+		return err == nil, err
+	}); err != nil {
+		return nil, err
+	}
+
+	videos := make([]model.Video, 0, len(result.Results))
+	for _, v := range result.Results {
+		videoURL, cover := tmdbVideoLinks(v)
+		if videoURL == "" {
+			continue
+		}
+
+		videoType, ok := tmdbVideoTypes[v.Type]
+		if !ok {
+			videoType = VideoTypeOther
+		}
+
+		videos = append(videos, model.Video{
+			ID:          v.Key,
+			Title:       v.Name,
+			Type:        videoType,
+			Cover:       cover,
+			URL:         videoURL,
+			Provider:    "tmdb",
+			Site:        v.Site,
+			Language:    v.Language,
+			PublishedAt: v.PublishedAt,
+		})
+	}
+
+	return videos, nil
+}
+
+// tmdbVideoLinks builds the watch URL and thumbnail for a hosted video
+func tmdbVideoLinks(v TMDBVideo) (string, string) {
+	switch v.Site {
+	case "YouTube":
+		return "https://www.youtube.com/watch?v=" + v.Key, "https://img.youtube.com/vi/" + v.Key + "/hqdefault.jpg"
+	case "Vimeo":
+		return "https://vimeo.com/" + v.Key, ""
+	}
+	return "", ""
+}
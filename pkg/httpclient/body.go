@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding is sent on every request. The transport's transparent
+// gzip handling is disabled, so decoding happens in readBody.
+const acceptEncoding = "gzip, deflate, br"
+
+// ErrBodyTooLarge is returned when a decoded response exceeds MaxBodyBytes
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// readBody decodes the response according to its Content-Encoding and reads
+// at most limit bytes of decoded content (limit <= 0 disables the check)
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	var reader io.Reader = resp.Body
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	case "deflate":
+		zr, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deflate body: %w", err)
+		}
+		defer zr.Close()
+		reader = zr
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	if limit <= 0 {
+		return io.ReadAll(reader)
+	}
+
+	// Read one extra byte to tell "exactly limit" from "too large"
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, limit)
+	}
+	return data, nil
+}
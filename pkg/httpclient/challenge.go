@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrBlocked is returned when Douban answers with an anti-bot challenge,
+// a login wall or a redirect to its security pages instead of real content
+var ErrBlocked = errors.New("blocked by douban")
+
+// BlockedError describes a blocked response
+type BlockedError struct {
+	URL    string
+	Proxy  string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	if e.Proxy != "" {
+		return fmt.Sprintf("blocked by douban (%s) via proxy %s", e.Reason, e.Proxy)
+	}
+	return fmt.Sprintf("blocked by douban (%s)", e.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrBlocked)
+func (e *BlockedError) Unwrap() error {
+	return ErrBlocked
+}
+
+// IsBlocked reports whether err was caused by a Douban challenge page
+func IsBlocked(err error) bool {
+	return errors.Is(err, ErrBlocked)
+}
+
+// Hosts Douban redirects to when it suspects a bot
+var challengeHosts = map[string]bool{
+	"sec.douban.com":      true,
+	"accounts.douban.com": true,
+}
+
+// Page fragments that only appear on challenge / login pages
+var challengeMarkers = []string{
+	"检测到有异常请求",
+	"有异常请求从你的 IP 发出",
+	"sec.douban.com/c",
+	"<title>禁止访问</title>",
+	"<title>登录豆瓣</title>",
+	"<title>登录跳转</title>",
+}
+
+// isChallengeURL reports whether u points to a Douban security or login host
+func isChallengeURL(u *url.URL) bool {
+	return u != nil && challengeHosts[strings.ToLower(u.Hostname())]
+}
+
+// checkRedirect stops following redirects into Douban's challenge hosts so
+// the redirect itself can be classified as blocked
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if isChallengeURL(req.URL) {
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
+// detectRedirectChallenge classifies a response that was (or would have
+// been) redirected to a challenge host. Returns an empty reason otherwise.
+func detectRedirectChallenge(resp *http.Response) string {
+	if isChallengeURL(resp.Request.URL) {
+		return "redirected to " + resp.Request.URL.Hostname()
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		if loc, err := resp.Location(); err == nil && isChallengeURL(loc) {
+			return "redirected to " + loc.Hostname()
+		}
+	}
+	return ""
+}
+
+// detectBodyChallenge inspects a successful response body for challenge
+// pages. Returns an empty reason if the body looks like real content.
+func detectBodyChallenge(targetURL string, body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '<' {
+		return ""
+	}
+
+	for _, marker := range challengeMarkers {
+		if bytes.Contains(trimmed, []byte(marker)) {
+			return "challenge page"
+		}
+	}
+
+	// JSON endpoints never legitimately answer with HTML
+	if u, err := url.Parse(targetURL); err == nil && strings.HasPrefix(u.Path, "/j/") {
+		return "html instead of json"
+	}
+
+	return ""
+}
@@ -1,67 +1,168 @@
 package httpclient
 
 import (
+	"crypto/tls"
 	"fmt"
-	"io"
 	"math"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
-// Random User-Agent pool - Updated for 2024-2025
-var userAgents = []string{
-	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
-	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
-	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Safari/605.1.15",
-	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:133.0) Gecko/20100101 Firefox/133.0",
-	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
-	"Mozilla/5.0 (iPhone; CPU iPhone OS 18_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Mobile/15E148 Safari/604.1",
-	"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+// proxyPenalty is how long a proxy is avoided after it got blocked
+const proxyPenalty = 10 * time.Minute
+
+// Options tunes the transport and response handling of a Client
+type Options struct {
+	Timeout               time.Duration // total request timeout
+	MaxBodyBytes          int64         // decoded body limit, <= 0 disables it
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	DisableHTTP2          bool
+}
+
+// DefaultOptions returns the default client options
+func DefaultOptions() Options {
+	return Options{
+		Timeout:               10 * time.Second,
+		MaxBodyBytes:          5 << 20, // 5 MB
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		DialTimeout:           5 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 8 * time.Second,
+	}
 }
 
 // Client is an HTTP client with retry and proxy support
 type Client struct {
-	httpClient *http.Client
-	proxies    []string
-	timeout    time.Duration
-	retries    int
-	retryDelay time.Duration
+	httpClient   *http.Client
+	proxies      []string
+	timeout      time.Duration
+	retries      int
+	retryDelay   time.Duration
+	maxBodyBytes int64
+
+	mu            sync.Mutex
+	penalties     map[string]time.Time // proxy -> avoid until
+	profiles      []Profile
+	proxyProfiles map[string]*Profile // proxy ("" = direct) -> sticky profile
+
+	sessions sessionPool // optional cookie sessions, see LoadSessions
 }
 
 // NewClient creates a new HTTP client
-func NewClient(proxies []string) *Client {
+func NewClient(proxies []string, opts Options) *Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   opts.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     !opts.DisableHTTP2,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableCompression:    true, // Accept-Encoding is negotiated and decoded in readBody
+	}
+	if opts.DisableHTTP2 {
+		// A non-nil empty map disables the automatic HTTP/2 upgrade
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Transport:     transport,
+			Timeout:       opts.Timeout,
+			CheckRedirect: checkRedirect,
 		},
-		proxies:    proxies,
-		timeout:    10 * time.Second,
-		retries:    3,
-		retryDelay: 1 * time.Second,
-	}
-}
+		proxies:      proxies,
+		timeout:      opts.Timeout,
+		retries:      3,
+		retryDelay:   1 * time.Second,
+		maxBodyBytes: opts.MaxBodyBytes,
+		penalties:    make(map[string]time.Time),
+		profiles:     defaultProfiles,
 
-// getRandomUserAgent returns a random user agent string
-func getRandomUserAgent() string {
-	return userAgents[rand.Intn(len(userAgents))]
+		proxyProfiles: make(map[string]*Profile),
+	}
 }
 
-// getRandomProxy returns a random proxy URL or empty string if none available
-func (c *Client) getRandomProxy() string {
+// getRandomProxy returns a random proxy URL or empty string if none available.
+// Proxies already tried for this request and penalised proxies are avoided
+// as long as an alternative exists.
+func (c *Client) getRandomProxy(tried map[string]bool) string {
 	if len(c.proxies) == 0 {
 		return ""
 	}
-	return c.proxies[rand.Intn(len(c.proxies))]
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var fresh, healthy []string
+	for _, p := range c.proxies {
+		if until, ok := c.penalties[p]; ok && now.After(until) {
+			delete(c.penalties, p)
+		}
+		if _, penalised := c.penalties[p]; penalised {
+			continue
+		}
+		healthy = append(healthy, p)
+		if !tried[p] {
+			fresh = append(fresh, p)
+		}
+	}
+
+	switch {
+	case len(fresh) > 0:
+		return fresh[rand.Intn(len(fresh))]
+	case len(healthy) > 0:
+		return healthy[rand.Intn(len(healthy))]
+	default:
+		return c.proxies[rand.Intn(len(c.proxies))]
+	}
+}
+
+// penalizeProxy keeps a proxy out of rotation for a while
+func (c *Client) penalizeProxy(proxy string) {
+	if proxy == "" {
+		return
+	}
+	c.mu.Lock()
+	c.penalties[proxy] = time.Now().Add(proxyPenalty)
+	delete(c.proxyProfiles, proxy) // come back with a fresh fingerprint
+	c.mu.Unlock()
+	log.Warn().Str("proxy", proxy).Dur("penalty", proxyPenalty).Msg("🚫 代理被豆瓣拦截，暂时移出轮询")
+}
+
+// proxyPenalized reports whether a proxy is currently out of rotation
+func (c *Client) proxyPenalized(proxy string) bool {
+	if proxy == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, ok := c.penalties[proxy]
+	return ok && time.Now().Before(until)
 }
 
 // convertToProxyURL converts a Douban URL to a proxy URL
-func (c *Client) convertToProxyURL(originalURL string) (string, bool) {
-	proxy := c.getRandomProxy()
+func (c *Client) convertToProxyURL(originalURL, proxy string) (string, bool) {
 	if proxy == "" {
 		return originalURL, false
 	}
@@ -83,10 +184,25 @@ func (c *Client) convertToProxyURL(originalURL string) (string, bool) {
 // Fetch makes an HTTP GET request with retry and proxy support
 func (c *Client) Fetch(targetURL string) ([]byte, error) {
 	var lastErr error
+	tried := make(map[string]bool)
+	triedSessions := make(map[*Session]bool)
 
 	for attempt := 1; attempt <= c.retries; attempt++ {
-		// Convert to proxy URL (may use different proxy each retry)
-		finalURL, useProxy := c.convertToProxyURL(targetURL)
+		// A session keeps its pinned proxy; anonymous requests prefer a proxy
+		// not yet tried for this request
+		session := c.sessions.acquire(triedSessions, c.proxyPenalized)
+		var proxy string
+		if session != nil {
+			triedSessions[session] = true
+			proxy = session.Proxy
+		} else {
+			proxy = c.getRandomProxy(tried)
+		}
+		finalURL, useProxy := c.convertToProxyURL(targetURL, proxy)
+		if !useProxy {
+			proxy = ""
+		}
+		tried[proxy] = true
 
 		req, err := http.NewRequest("GET", finalURL, nil)
 		if err != nil {
@@ -94,18 +210,31 @@ func (c *Client) Fetch(targetURL string) ([]byte, error) {
 			continue
 		}
 
-		// Set headers only when not using proxy (proxy handles headers)
-		if !useProxy {
-			req.Header.Set("User-Agent", getRandomUserAgent())
+		// Set headers only when not using proxy (proxy handles headers).
+		// Sessions always send their own identity so it stays consistent.
+		if !useProxy || session != nil {
+			profile := c.profileForProxy(proxy)
+			if session != nil {
+				profile = session.Profile
+			}
+			profile.apply(req)
 			req.Header.Set("Referer", "https://movie.douban.com/")
-			req.Header.Set("Accept", "application/json, text/plain, */*")
-			req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
 			req.Header.Set("Connection", "keep-alive")
 			req.Header.Set("Cache-Control", "no-cache")
 		}
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		if session != nil {
+			for _, cookie := range session.jar.Cookies(doubanCookieURL) {
+				req.AddCookie(cookie)
+			}
+		}
+
+		var timing requestTiming
+		req = req.WithContext(timing.withTrace(req.Context()))
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			timing.log(targetURL, proxy, 0)
 			lastErr = err
 			log.Warn().
 				Int("attempt", attempt).
@@ -120,10 +249,25 @@ func (c *Client) Fetch(targetURL string) ([]byte, error) {
 			continue
 		}
 
+		// Successful responses are logged once the body has been read
+		if resp.StatusCode != http.StatusOK {
+			timing.log(targetURL, proxy, resp.StatusCode)
+		}
+
+		// Redirects to sec.douban.com / accounts.douban.com mean we are blocked
+		if reason := detectRedirectChallenge(resp); reason != "" {
+			resp.Body.Close()
+			c.sessions.markBlocked(session)
+			lastErr = c.handleBlocked(targetURL, proxy, reason, attempt)
+			continue
+		}
+
 		// Handle rate limiting
 		if resp.StatusCode == 403 || resp.StatusCode == 429 {
 			resp.Body.Close() // 立即关闭，避免泄漏
 			lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+			c.penalizeProxy(proxy)
+			c.sessions.markBlocked(session)
 			log.Warn().
 				Int("attempt", attempt).
 				Int("status", resp.StatusCode).
@@ -143,21 +287,51 @@ func (c *Client) Fetch(targetURL string) ([]byte, error) {
 			continue
 		}
 
-		// 读取并立即关闭 body
-		body, err := io.ReadAll(resp.Body)
+		// 读取（解压、限制大小）并立即关闭 body
+		body, err := readBody(resp, c.maxBodyBytes)
 		resp.Body.Close() // 立即关闭，不使用 defer
+		timing.log(targetURL, proxy, resp.StatusCode)
 
 		if err != nil {
 			lastErr = err
 			continue
 		}
 
+		// HTTP 200 with an anti-bot / login page instead of content
+		if reason := detectBodyChallenge(targetURL, body); reason != "" {
+			c.sessions.markBlocked(session)
+			lastErr = c.handleBlocked(targetURL, proxy, reason, attempt)
+			continue
+		}
+
+		c.sessions.markSuccess(session, resp)
+
 		return body, nil
 	}
 
 	return nil, fmt.Errorf("all retries failed: %w", lastErr)
 }
 
+// handleBlocked penalises the proxy that served a challenge page and waits
+// before the next attempt when there is no other proxy to switch to
+func (c *Client) handleBlocked(targetURL, proxy, reason string, attempt int) error {
+	log.Warn().
+		Int("attempt", attempt).
+		Str("reason", reason).
+		Str("proxy", proxy).
+		Str("url", targetURL).
+		Msg("Request blocked by douban")
+
+	c.penalizeProxy(proxy)
+
+	if proxy == "" && attempt < c.retries {
+		waitTime := c.retryDelay * time.Duration(math.Pow(2, float64(attempt-1)))
+		time.Sleep(waitTime)
+	}
+
+	return &BlockedError{URL: targetURL, Proxy: proxy, Reason: reason}
+}
+
 // FetchJSON is a convenience method for fetching JSON data
 func (c *Client) FetchJSON(targetURL string) ([]byte, error) {
 	return c.Fetch(targetURL)
@@ -0,0 +1,171 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+)
+
+// Profile is a browser fingerprint: a User-Agent together with the headers
+// that browser really sends, so e.g. Chrome UAs always carry client hints
+type Profile struct {
+	Name      string            `json:"name"`
+	UserAgent string            `json:"user_agent"`
+	Headers   map[string]string `json:"headers"`
+}
+
+// apply sets the profile's User-Agent and headers on a request
+func (p *Profile) apply(req *http.Request) {
+	req.Header.Set("User-Agent", p.UserAgent)
+	for name, value := range p.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+const (
+	chromeAccept   = "application/json, text/plain, */*"
+	chromeLanguage = "zh-CN,zh;q=0.9,en;q=0.8"
+	chromeSecChUA  = `"Google Chrome";v="131", "Chromium";v="131", "Not_A Brand";v="24"`
+)
+
+// Default profiles - Updated for 2024-2025
+var defaultProfiles = []Profile{
+	{
+		Name:      "chrome-macos",
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		Headers: map[string]string{
+			"Accept":             chromeAccept,
+			"Accept-Language":    chromeLanguage,
+			"sec-ch-ua":          chromeSecChUA,
+			"sec-ch-ua-mobile":   "?0",
+			"sec-ch-ua-platform": `"macOS"`,
+		},
+	},
+	{
+		Name:      "chrome-windows",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		Headers: map[string]string{
+			"Accept":             chromeAccept,
+			"Accept-Language":    chromeLanguage,
+			"sec-ch-ua":          chromeSecChUA,
+			"sec-ch-ua-mobile":   "?0",
+			"sec-ch-ua-platform": `"Windows"`,
+		},
+	},
+	{
+		Name:      "chrome-linux",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		Headers: map[string]string{
+			"Accept":             chromeAccept,
+			"Accept-Language":    chromeLanguage,
+			"sec-ch-ua":          chromeSecChUA,
+			"sec-ch-ua-mobile":   "?0",
+			"sec-ch-ua-platform": `"Linux"`,
+		},
+	},
+	{
+		Name:      "chrome-android",
+		UserAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+		Headers: map[string]string{
+			"Accept":             chromeAccept,
+			"Accept-Language":    chromeLanguage,
+			"sec-ch-ua":          chromeSecChUA,
+			"sec-ch-ua-mobile":   "?1",
+			"sec-ch-ua-platform": `"Android"`,
+		},
+	},
+	{
+		// Safari and Firefox do not send client hints
+		Name:      "safari-macos",
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Safari/605.1.15",
+		Headers: map[string]string{
+			"Accept":          "application/json, text/plain, */*",
+			"Accept-Language": "zh-CN,zh-Hans;q=0.9",
+		},
+	},
+	{
+		Name:      "safari-ios",
+		UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 18_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Mobile/15E148 Safari/604.1",
+		Headers: map[string]string{
+			"Accept":          "application/json, text/plain, */*",
+			"Accept-Language": "zh-CN,zh-Hans;q=0.9",
+		},
+	},
+	{
+		Name:      "firefox-windows",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:133.0) Gecko/20100101 Firefox/133.0",
+		Headers: map[string]string{
+			"Accept":          "application/json, text/plain, */*",
+			"Accept-Language": "zh-CN,zh;q=0.8,zh-TW;q=0.7,zh-HK;q=0.5,en-US;q=0.3,en;q=0.2",
+		},
+	},
+}
+
+// LoadProfiles replaces the built-in User-Agent profiles with the ones from
+// a JSON file. Existing proxy assignments are reset.
+func (c *Client) LoadProfiles(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read profile file: %w", err)
+	}
+
+	var profiles []Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return 0, fmt.Errorf("failed to parse profile file: %w", err)
+	}
+
+	valid := make([]Profile, 0, len(profiles))
+	for i, p := range profiles {
+		if p.UserAgent == "" {
+			continue
+		}
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("profile-%d", i+1)
+		}
+		valid = append(valid, p)
+	}
+	if len(valid) == 0 {
+		return 0, fmt.Errorf("profile file contains no usable profiles")
+	}
+
+	c.mu.Lock()
+	c.profiles = valid
+	c.proxyProfiles = make(map[string]*Profile)
+	c.mu.Unlock()
+
+	return len(valid), nil
+}
+
+// randomProfile returns a random profile
+func (c *Client) randomProfile() *Profile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &c.profiles[rand.Intn(len(c.profiles))]
+}
+
+// findProfile returns the profile with the given name or User-Agent
+func (c *Client) findProfile(nameOrUA string) *Profile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.profiles {
+		if c.profiles[i].Name == nameOrUA || c.profiles[i].UserAgent == nameOrUA {
+			return &c.profiles[i]
+		}
+	}
+	return nil
+}
+
+// profileForProxy returns the profile pinned to a proxy ("" for direct
+// requests), assigning a random one on first use
+func (c *Client) profileForProxy(proxy string) *Profile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.proxyProfiles[proxy]; ok {
+		return p
+	}
+	p := &c.profiles[rand.Intn(len(c.profiles))]
+	c.proxyProfiles[proxy] = p
+	return p
+}
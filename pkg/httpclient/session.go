@@ -0,0 +1,234 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// sessionCooldown is multiplied by the number of consecutive blocks
+	sessionCooldown = 5 * time.Minute
+	// maxSessionBlocks retires a session after this many consecutive blocks
+	maxSessionBlocks = 3
+)
+
+// Cookies are stored against Douban itself, not the proxy host, so the same
+// identity is sent no matter which URL the request is rewritten to
+var doubanCookieURL = &url.URL{Scheme: "https", Host: "movie.douban.com", Path: "/"}
+
+// sessionConfig is one entry of the session secrets file
+type sessionConfig struct {
+	ID        string            `json:"id"`
+	Cookies   map[string]string `json:"cookies"`
+	Proxy     string            `json:"proxy,omitempty"`
+	Profile   string            `json:"profile,omitempty"`    // profile name
+	UserAgent string            `json:"user_agent,omitempty"` // used when no profile is given
+}
+
+// Session is a Douban identity: a cookie jar pinned to one proxy and UA profile
+type Session struct {
+	ID      string
+	Proxy   string
+	Profile *Profile
+
+	jar           http.CookieJar
+	uses          int64
+	blocks        int
+	cooldownUntil time.Time
+	retired       bool
+}
+
+// SessionStatus is a snapshot of a session's health. Cookie values are never exposed.
+type SessionStatus struct {
+	ID          string `json:"id"`
+	Profile     string `json:"profile"`
+	Uses        int64  `json:"uses"`
+	Blocks      int    `json:"blocks"`
+	CoolingDown bool   `json:"cooling_down"`
+	Retired     bool   `json:"retired"`
+}
+
+// sessionPool hands out sessions round-robin, skipping blocked ones
+type sessionPool struct {
+	mu       sync.Mutex
+	sessions []*Session
+	next     int
+}
+
+// LoadSessions loads cookie sessions from a JSON secrets file. Sessions
+// without a pinned proxy are spread across the configured proxies.
+func (c *Client) LoadSessions(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var configs []sessionConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return 0, fmt.Errorf("failed to parse session file: %w", err)
+	}
+
+	sessions := make([]*Session, 0, len(configs))
+	for i, cfg := range configs {
+		if len(cfg.Cookies) == 0 {
+			continue
+		}
+
+		jar, _ := cookiejar.New(nil)
+		cookies := make([]*http.Cookie, 0, len(cfg.Cookies))
+		for name, value := range cfg.Cookies {
+			cookies = append(cookies, &http.Cookie{
+				Name:   name,
+				Value:  value,
+				Domain: "douban.com",
+				Path:   "/",
+			})
+		}
+		jar.SetCookies(doubanCookieURL, cookies)
+
+		s := &Session{
+			ID:    cfg.ID,
+			Proxy: cfg.Proxy,
+			jar:   jar,
+		}
+		if s.ID == "" {
+			s.ID = fmt.Sprintf("session-%d", i+1)
+		}
+		if s.Proxy == "" && len(c.proxies) > 0 {
+			s.Proxy = c.proxies[i%len(c.proxies)]
+		}
+		s.Profile = c.sessionProfile(cfg)
+		sessions = append(sessions, s)
+	}
+
+	c.sessions.mu.Lock()
+	c.sessions.sessions = sessions
+	c.sessions.next = 0
+	c.sessions.mu.Unlock()
+
+	return len(sessions), nil
+}
+
+// sessionProfile resolves the UA profile for a session entry. A bare
+// user_agent without a matching profile only gets the basic headers.
+func (c *Client) sessionProfile(cfg sessionConfig) *Profile {
+	if cfg.Profile != "" {
+		if p := c.findProfile(cfg.Profile); p != nil {
+			return p
+		}
+		log.Warn().Str("session", cfg.ID).Str("profile", cfg.Profile).Msg("Unknown UA profile, using a random one")
+	}
+	if cfg.UserAgent != "" {
+		if p := c.findProfile(cfg.UserAgent); p != nil {
+			return p
+		}
+		return &Profile{
+			Name:      "custom",
+			UserAgent: cfg.UserAgent,
+			Headers: map[string]string{
+				"Accept":          "application/json, text/plain, */*",
+				"Accept-Language": "zh-CN,zh;q=0.9,en;q=0.8",
+			},
+		}
+	}
+	return c.randomProfile()
+}
+
+// acquire returns the next usable session not yet tried for this request,
+// or nil when the client should fall back to anonymous requests. Sessions
+// pinned to a penalised proxy are skipped until the penalty expires.
+func (p *sessionPool) acquire(tried map[*Session]bool, penalized func(proxy string) bool) *Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for i := 0; i < len(p.sessions); i++ {
+		s := p.sessions[(p.next+i)%len(p.sessions)]
+		if s.retired || now.Before(s.cooldownUntil) || tried[s] || penalized(s.Proxy) {
+			continue
+		}
+		p.next = (p.next + i + 1) % len(p.sessions)
+		s.uses++
+		return s
+	}
+	return nil
+}
+
+// markSuccess resets the block counter and stores rotated cookies
+func (p *sessionPool) markSuccess(s *Session, resp *http.Response) {
+	if s == nil {
+		return
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		s.jar.SetCookies(doubanCookieURL, cookies)
+	}
+
+	p.mu.Lock()
+	s.blocks = 0
+	p.mu.Unlock()
+}
+
+// markBlocked puts a session on cooldown and retires it after repeated blocks
+func (p *sessionPool) markBlocked(s *Session) {
+	if s == nil {
+		return
+	}
+
+	p.mu.Lock()
+	s.blocks++
+	blocks := s.blocks
+	s.cooldownUntil = time.Now().Add(sessionCooldown * time.Duration(blocks))
+	retired := blocks >= maxSessionBlocks
+	s.retired = retired
+	p.mu.Unlock()
+
+	if retired {
+		log.Warn().Str("session", s.ID).Msg("🪦 豆瓣会话多次被拦截，已停用")
+	} else {
+		log.Warn().Str("session", s.ID).Int("blocks", blocks).Msg("🍪 豆瓣会话被拦截，切换会话")
+	}
+}
+
+// status returns a snapshot of all sessions
+func (p *sessionPool) status() []SessionStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	result := make([]SessionStatus, len(p.sessions))
+	for i, s := range p.sessions {
+		result[i] = SessionStatus{
+			ID:          s.ID,
+			Profile:     s.Profile.Name,
+			Uses:        s.uses,
+			Blocks:      s.blocks,
+			CoolingDown: now.Before(s.cooldownUntil),
+			Retired:     s.retired,
+		}
+	}
+	return result
+}
+
+// SessionStatus returns the health of all loaded sessions
+func (c *Client) SessionStatus() []SessionStatus {
+	return c.sessions.status()
+}
+
+// ActiveSessionCount returns the number of sessions that have not been retired
+func (c *Client) ActiveSessionCount() int {
+	count := 0
+	for _, s := range c.sessions.status() {
+		if !s.Retired {
+			count++
+		}
+	}
+	return count
+}
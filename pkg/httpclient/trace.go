@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// requestTiming records the connection phases of a single request. Trace
+// callbacks may run on transport goroutines, so fields are guarded by mu.
+type requestTiming struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// withTrace attaches an httptrace.ClientTrace that fills in t
+func (t *requestTiming) withTrace(ctx context.Context) context.Context {
+	t.stamp(&t.start)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { t.stamp(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.stamp(&t.dnsDone) },
+		ConnectStart:      func(string, string) { t.stamp(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { t.stamp(&t.connectDone) },
+		TLSHandshakeStart: func() { t.stamp(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.stamp(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() { t.stamp(&t.firstByte) },
+	})
+}
+
+// stamp sets one of t's timestamps to now
+func (t *requestTiming) stamp(field *time.Time) {
+	t.mu.Lock()
+	*field = time.Now()
+	t.mu.Unlock()
+}
+
+// phase returns the duration between two timestamps, or 0 if the phase did not happen
+func phase(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// log writes the request timing at debug level. status is 0 when the
+// request failed without a response.
+func (t *requestTiming) log(targetURL, proxy string, status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	log.Debug().
+		Str("url", targetURL).
+		Str("proxy", proxy).
+		Int("status", status).
+		Bool("reused", t.reused).
+		Dur("dns", phase(t.dnsStart, t.dnsDone)).
+		Dur("connect", phase(t.connectStart, t.connectDone)).
+		Dur("tls", phase(t.tlsStart, t.tlsDone)).
+		Dur("ttfb", phase(t.start, t.firstByte)).
+		Dur("total", time.Since(t.start)).
+		Msg("Request timing")
+}